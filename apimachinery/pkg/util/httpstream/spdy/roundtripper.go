@@ -73,6 +73,13 @@ func NewSpdyRoundTripper(tlsConfig *tls.Config) *SpdyRoundTripper {
 	return &SpdyRoundTripper{tlsConfig: tlsConfig}
 }
 
+// NewRoundTripperWithProxy creates a new SpdyRoundTripper that will use the
+// specified tlsConfig, and connect through the proxy returned by proxier. The
+// http, https and socks5 proxy schemes are supported.
+func NewRoundTripperWithProxy(tlsConfig *tls.Config, proxier func(*http.Request) (*url.URL, error)) *SpdyRoundTripper {
+	return &SpdyRoundTripper{tlsConfig: tlsConfig, proxier: proxier}
+}
+
 // implements pkg/util/net.TLSClientConfigHolder for proper TLS checking during proxying with a spdy roundtripper
 func (s *SpdyRoundTripper) TLSClientConfig() *tls.Config {
 	return s.tlsConfig
@@ -94,6 +101,18 @@ func (s *SpdyRoundTripper) dial(req *http.Request) (net.Conn, error) {
 		return s.dialWithoutProxy(req.URL)
 	}
 
+	switch proxyURL.Scheme {
+	case "socks5":
+		return s.dialWithSocks5Proxy(req, proxyURL)
+	case "http", "https", "":
+		return s.dialWithHttpProxy(req, proxyURL)
+	}
+	return nil, fmt.Errorf("proxy URL scheme not supported: %s", proxyURL.Scheme)
+}
+
+// dialWithHttpProxy dials the host specified by req through the HTTP proxy at
+// proxyURL, with a CONNECT request.
+func (s *SpdyRoundTripper) dialWithHttpProxy(req *http.Request, proxyURL *url.URL) (net.Conn, error) {
 	// ensure we use a canonical host with proxyReq
 	targetHost := netutil.CanonicalAddr(req.URL)
 
@@ -121,12 +140,42 @@ func (s *SpdyRoundTripper) dial(req *http.Request) (net.Conn, error) {
 	}
 
 	rwc, _ := proxyClientConn.Hijack()
+	return s.tlsConn(rwc, req.URL)
+}
+
+// dialWithSocks5Proxy dials the host specified by req through the SOCKS5
+// proxy at proxyURL.
+func (s *SpdyRoundTripper) dialWithSocks5Proxy(req *http.Request, proxyURL *url.URL) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if _, _, err := net.SplitHostPort(proxyAddr); err != nil {
+		proxyAddr = net.JoinHostPort(proxyAddr, "1080")
+	}
+
+	var conn net.Conn
+	var err error
+	if s.Dialer == nil {
+		conn, err = net.Dial("tcp", proxyAddr)
+	} else {
+		conn, err = s.Dialer.Dial("tcp", proxyAddr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := socks5Connect(conn, proxyURL.User, netutil.CanonicalAddr(req.URL)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s.tlsConn(conn, req.URL)
+}
 
-	if req.URL.Scheme != "https" {
+// tlsConn starts TLS over a connection established through a proxy to the
+// host of url, if url uses https.
+func (s *SpdyRoundTripper) tlsConn(rwc net.Conn, url *url.URL) (net.Conn, error) {
+	if url.Scheme != "https" {
 		return rwc, nil
 	}
 
-	host, _, err := net.SplitHostPort(targetHost)
+	host, _, err := net.SplitHostPort(netutil.CanonicalAddr(url))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,177 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// SOCKS5 protocol constants, see RFC 1928 and RFC 1929.
+const (
+	socks5Version = 5
+
+	socks5AuthNone             = 0
+	socks5AuthUsernamePassword = 2
+	socks5AuthNoAcceptable     = 0xff
+
+	socks5CommandConnect = 1
+
+	socks5AddressIPv4   = 1
+	socks5AddressDomain = 3
+	socks5AddressIPv6   = 4
+)
+
+// socks5Connect asks the SOCKS5 proxy at the other end of conn to connect to
+// addr, authenticating with user if it is set.
+func socks5Connect(conn net.Conn, user *url.Userinfo, addr string) error {
+	host, portString, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil || port < 1 || port > 0xffff {
+		return fmt.Errorf("socks5: invalid port %q", portString)
+	}
+
+	methods := []byte{socks5AuthNone}
+	if user != nil {
+		methods = append(methods, socks5AuthUsernamePassword)
+	}
+	if _, err := conn.Write(append([]byte{socks5Version, byte(len(methods))}, methods...)); err != nil {
+		return fmt.Errorf("socks5: failed to write greeting: %v", err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: failed to read greeting: %v", err)
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("socks5: unexpected protocol version %d", reply[0])
+	}
+	switch reply[1] {
+	case socks5AuthNone:
+	case socks5AuthUsernamePassword:
+		if user == nil {
+			return errors.New("socks5: proxy requires authentication")
+		}
+		if err := socks5Authenticate(conn, user); err != nil {
+			return err
+		}
+	case socks5AuthNoAcceptable:
+		return errors.New("socks5: no acceptable authentication methods")
+	default:
+		return fmt.Errorf("socks5: unsupported authentication method %d", reply[1])
+	}
+
+	request := []byte{socks5Version, socks5CommandConnect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("socks5: host name too long: %s", host)
+		}
+		request = append(request, socks5AddressDomain, byte(len(host)))
+		request = append(request, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		request = append(request, socks5AddressIPv4)
+		request = append(request, ip4...)
+	} else {
+		request = append(request, socks5AddressIPv6)
+		request = append(request, ip...)
+	}
+	request = append(request, byte(port>>8), byte(port))
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("socks5: failed to write connect request: %v", err)
+	}
+
+	// version, reply, reserved and address type, followed by the bound
+	// address and port which are not needed
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("socks5: failed to read connect reply: %v", err)
+	}
+	if header[1] != 0 {
+		return fmt.Errorf("socks5: proxy failed to connect to %s: %s", addr, socks5ReplyMessage(header[1]))
+	}
+	var boundLength int
+	switch header[3] {
+	case socks5AddressIPv4:
+		boundLength = net.IPv4len
+	case socks5AddressIPv6:
+		boundLength = net.IPv6len
+	case socks5AddressDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("socks5: failed to read connect reply: %v", err)
+		}
+		boundLength = int(length[0])
+	default:
+		return fmt.Errorf("socks5: unknown address type %d", header[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, boundLength+2)); err != nil {
+		return fmt.Errorf("socks5: failed to read connect reply: %v", err)
+	}
+	return nil
+}
+
+// socks5Authenticate performs a username/password authentication.
+func socks5Authenticate(conn net.Conn, user *url.Userinfo) error {
+	username := user.Username()
+	password, _ := user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("socks5: username or password too long")
+	}
+	request := []byte{1, byte(len(username))}
+	request = append(request, username...)
+	request = append(request, byte(len(password)))
+	request = append(request, password...)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("socks5: failed to write authentication request: %v", err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: failed to read authentication reply: %v", err)
+	}
+	if reply[1] != 0 {
+		return errors.New("socks5: username/password authentication failed")
+	}
+	return nil
+}
+
+func socks5ReplyMessage(code byte) string {
+	switch code {
+	case 1:
+		return "general SOCKS server failure"
+	case 2:
+		return "connection not allowed by ruleset"
+	case 3:
+		return "network unreachable"
+	case 4:
+		return "host unreachable"
+	case 5:
+		return "connection refused"
+	case 6:
+		return "TTL expired"
+	case 7:
+		return "command not supported"
+	case 8:
+		return "address type not supported"
+	}
+	return fmt.Sprintf("unknown error %d", code)
+}
@@ -26,6 +26,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"golang.org/x/net/http2"
@@ -76,13 +77,27 @@ func SetOldTransportDefaults(t *http.Transport) *http.Transport {
 // SetTransportDefaults applies the defaults from http.DefaultTransport
 // for the Proxy, Dial, and TLSHandshakeTimeout fields if unset
 func SetTransportDefaults(t *http.Transport) *http.Transport {
+	return SetTransportDefaultsWithHealthCheck(t, 0, 0)
+}
+
+// SetTransportDefaultsWithHealthCheck is like SetTransportDefaults, but also
+// health checks the http2 connections of t: a PING frame is sent on a
+// connection that received no frame for readIdleTimeout, and the connection
+// is closed if the PING isn't answered within pingTimeout. A zero
+// readIdleTimeout disables health checks, a zero pingTimeout defaults to 15
+// seconds.
+func SetTransportDefaultsWithHealthCheck(t *http.Transport, readIdleTimeout, pingTimeout time.Duration) *http.Transport {
 	t = SetOldTransportDefaults(t)
 	// Allow clients to disable http2 if needed.
 	if s := os.Getenv("DISABLE_HTTP2"); len(s) > 0 {
 		glog.Infof("HTTP2 has been explicitly disabled")
 	} else {
-		if err := http2.ConfigureTransport(t); err != nil {
+		t2, err := http2.ConfigureTransports(t)
+		if err != nil {
 			glog.Warningf("Transport failed http2 configuration: %v", err)
+		} else {
+			t2.ReadIdleTimeout = readIdleTimeout
+			t2.PingTimeout = pingTimeout
 		}
 	}
 	return t
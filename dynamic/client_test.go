@@ -148,6 +148,41 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListWithLimit(t *testing.T) {
+	gv := &schema.GroupVersion{Group: "gtest", Version: "vtest"}
+	resource := &metav1.APIResource{Name: "rtest", Namespaced: true}
+	cl, srv, err := getClientServer(gv, func(w http.ResponseWriter, r *http.Request) {
+		if limit := r.URL.Query().Get("limit"); limit != "1" {
+			t.Errorf("List got limit %q, wanted 1", limit)
+		}
+		if cont := r.URL.Query().Get("continue"); cont != "token1" {
+			t.Errorf("List got continue %q, wanted token1", cont)
+		}
+		w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+		w.Write([]byte(`{"apiVersion": "vTest", "kind": "rTestList", "metadata": {"continue": "token2"}, "items": [` +
+			string(getJSON("vTest", "rTest", "item2")) + `]}`))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when creating client: %v", err)
+	}
+	defer srv.Close()
+
+	got, err := cl.Resource(resource, "nstest").List(&metav1.ListOptions{Limit: 1, Continue: "token1"})
+	if err != nil {
+		t.Fatalf("unexpected error when listing: %v", err)
+	}
+	list, ok := got.(*unstructured.UnstructuredList)
+	if !ok {
+		t.Fatalf("expected an *unstructured.UnstructuredList, got %T", got)
+	}
+	if list.GetContinue() != "token2" {
+		t.Errorf("expected continue token2, got %q", list.GetContinue())
+	}
+	if len(list.Items) != 1 {
+		t.Errorf("expected one item, got %d", len(list.Items))
+	}
+}
+
 func TestGet(t *testing.T) {
 	tcs := []struct {
 		namespace string
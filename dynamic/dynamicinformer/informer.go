@@ -0,0 +1,194 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dynamicinformer provides shared informers for arbitrary resources,
+// identified by GroupVersionResource, that are served through the dynamic
+// client.  Every object stored by these informers is an
+// *unstructured.Unstructured.
+package dynamicinformer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1/unstructured"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime/schema"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/watch"
+	"github.com/lavalamp/client-go-flat/dynamic"
+	"github.com/lavalamp/client-go-flat/informers"
+	"github.com/lavalamp/client-go-flat/informers/internalinterfaces"
+	"github.com/lavalamp/client-go-flat/tools/cache"
+)
+
+// DynamicSharedInformerFactory provides access to a shared informer and lister for dynamic client
+type DynamicSharedInformerFactory interface {
+	// Start initializes all requested informers.
+	Start(stopCh <-chan struct{})
+	// ForResource returns the shared informer for the resource, creating it the first
+	// time the resource is requested.
+	ForResource(gvr schema.GroupVersionResource) (informers.GenericInformer, error)
+	// WaitForCacheSync waits for all started informers' cache were synced.
+	WaitForCacheSync(stopCh <-chan struct{}) map[schema.GroupVersionResource]bool
+}
+
+// NewDynamicSharedInformerFactory constructs a new instance of dynamicSharedInformerFactory for all namespaces.
+func NewDynamicSharedInformerFactory(pool dynamic.ClientPool, defaultResync time.Duration) DynamicSharedInformerFactory {
+	return NewFilteredDynamicSharedInformerFactory(pool, defaultResync, metav1.NamespaceAll, nil)
+}
+
+// NewFilteredDynamicSharedInformerFactory constructs a new instance of dynamicSharedInformerFactory.
+// Listers obtained via this factory will be subject to the same filters as specified here,
+// so a label selector set by tweakListOptions limits what every informer caches.
+// A namespace other than metav1.NamespaceAll may only be used for namespaced resources.
+func NewFilteredDynamicSharedInformerFactory(pool dynamic.ClientPool, defaultResync time.Duration, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) DynamicSharedInformerFactory {
+	return &dynamicSharedInformerFactory{
+		pool:             pool,
+		defaultResync:    defaultResync,
+		namespace:        namespace,
+		tweakListOptions: tweakListOptions,
+		informers:        map[schema.GroupVersionResource]informers.GenericInformer{},
+		startedInformers: map[schema.GroupVersionResource]bool{},
+	}
+}
+
+type dynamicSharedInformerFactory struct {
+	pool             dynamic.ClientPool
+	defaultResync    time.Duration
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+
+	lock      sync.Mutex
+	informers map[schema.GroupVersionResource]informers.GenericInformer
+	// startedInformers is used for tracking which informers have been started.
+	// This allows Start() to be called multiple times safely.
+	startedInformers map[schema.GroupVersionResource]bool
+}
+
+var _ DynamicSharedInformerFactory = &dynamicSharedInformerFactory{}
+
+func (f *dynamicSharedInformerFactory) ForResource(gvr schema.GroupVersionResource) (informers.GenericInformer, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if informer, exists := f.informers[gvr]; exists {
+		return informer, nil
+	}
+
+	client, err := f.pool.ClientForGroupVersionResource(gvr)
+	if err != nil {
+		return nil, err
+	}
+	informer := NewFilteredDynamicInformer(client, gvr, f.namespace, f.defaultResync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+	f.informers[gvr] = informer
+	return informer, nil
+}
+
+func (f *dynamicSharedInformerFactory) Start(stopCh <-chan struct{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for gvr, informer := range f.informers {
+		if !f.startedInformers[gvr] {
+			go informer.Informer().Run(stopCh)
+			f.startedInformers[gvr] = true
+		}
+	}
+}
+
+func (f *dynamicSharedInformerFactory) WaitForCacheSync(stopCh <-chan struct{}) map[schema.GroupVersionResource]bool {
+	informers := func() map[schema.GroupVersionResource]cache.SharedIndexInformer {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		informers := map[schema.GroupVersionResource]cache.SharedIndexInformer{}
+		for gvr, informer := range f.informers {
+			if f.startedInformers[gvr] {
+				informers[gvr] = informer.Informer()
+			}
+		}
+		return informers
+	}()
+
+	res := map[schema.GroupVersionResource]bool{}
+	for gvr, informer := range informers {
+		res[gvr] = cache.WaitForCacheSync(stopCh, informer.HasSynced)
+	}
+	return res
+}
+
+// NewDynamicInformer constructs a new informer for the resource served by client.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewDynamicInformer(client *dynamic.Client, gvr schema.GroupVersionResource, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) informers.GenericInformer {
+	return NewFilteredDynamicInformer(client, gvr, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredDynamicInformer constructs a new informer for the resource served by client.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredDynamicInformer(client *dynamic.Client, gvr schema.GroupVersionResource, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) informers.GenericInformer {
+	resource := &metav1.APIResource{Name: gvr.Resource, Namespaced: namespace != metav1.NamespaceAll}
+	resourceClient := client.Resource(resource, namespace)
+
+	return &dynamicInformer{
+		gvr: gvr,
+		informer: cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					if tweakListOptions != nil {
+						tweakListOptions(&options)
+					}
+					obj, err := resourceClient.List(&options)
+					if err != nil {
+						return nil, err
+					}
+					if _, ok := obj.(*unstructured.UnstructuredList); !ok {
+						return nil, fmt.Errorf("expected *unstructured.UnstructuredList listing %v, got %T", gvr, obj)
+					}
+					return obj, nil
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					if tweakListOptions != nil {
+						tweakListOptions(&options)
+					}
+					return resourceClient.Watch(&options)
+				},
+			},
+			&unstructured.Unstructured{},
+			resyncPeriod,
+			indexers,
+		),
+	}
+}
+
+type dynamicInformer struct {
+	informer cache.SharedIndexInformer
+	gvr      schema.GroupVersionResource
+}
+
+var _ informers.GenericInformer = &dynamicInformer{}
+
+func (d *dynamicInformer) Informer() cache.SharedIndexInformer {
+	return d.informer
+}
+
+// Lister returns a GenericLister whose objects are all *unstructured.Unstructured.
+func (d *dynamicInformer) Lister() cache.GenericLister {
+	return cache.NewGenericLister(d.informer.GetIndexer(), d.gvr.GroupResource())
+}
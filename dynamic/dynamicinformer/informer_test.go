@@ -0,0 +1,140 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicinformer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1/unstructured"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/labels"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime/schema"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/dynamic"
+	restclient "github.com/lavalamp/client-go-flat/rest"
+)
+
+const widgetList = `{"apiVersion": "example.com/v1", "kind": "WidgetList", "metadata": {"resourceVersion": "10"}, "items": [
+	{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"name": "w1", "namespace": "ns1", "resourceVersion": "5"}},
+	{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"name": "w2", "namespace": "ns1", "resourceVersion": "6"}}
+]}`
+
+type fakeClientPool struct {
+	host string
+}
+
+func (p *fakeClientPool) ClientForGroupVersionResource(resource schema.GroupVersionResource) (*dynamic.Client, error) {
+	return p.ClientForGroupVersionKind(schema.GroupVersionKind{Group: resource.Group, Version: resource.Version})
+}
+
+func (p *fakeClientPool) ClientForGroupVersionKind(kind schema.GroupVersionKind) (*dynamic.Client, error) {
+	gv := kind.GroupVersion()
+	return dynamic.NewClient(&restclient.Config{
+		Host:          p.host,
+		APIPath:       "/apis",
+		ContentConfig: restclient.ContentConfig{GroupVersion: &gv},
+	})
+}
+
+func TestDynamicSharedInformerFactory(t *testing.T) {
+	var lock sync.Mutex
+	var listPaths, selectors []string
+	stopCh := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/apis/example.com/v1/watch/") {
+			// hold the watch open without events until the test is over
+			w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			select {
+			case <-stopCh:
+			case <-time.After(wait.ForeverTestTimeout):
+			}
+			return
+		}
+		lock.Lock()
+		listPaths = append(listPaths, r.URL.Path)
+		selectors = append(selectors, r.URL.Query().Get("labelSelector"))
+		lock.Unlock()
+		w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+		w.Write([]byte(widgetList))
+	}))
+	defer srv.Close()
+	defer close(stopCh)
+
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	factory := NewFilteredDynamicSharedInformerFactory(&fakeClientPool{host: srv.URL}, 0, "ns1", func(options *metav1.ListOptions) {
+		options.LabelSelector = "tier=frontend"
+	})
+
+	informer, err := factory.ForResource(gvr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, err := factory.ForResource(gvr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if informer != again {
+		t.Errorf("expected the same informer for repeated requests of the same resource")
+	}
+
+	factory.Start(stopCh)
+	synced := factory.WaitForCacheSync(stopCh)
+	if len(synced) != 1 || !synced[gvr] {
+		t.Fatalf("unexpected sync status: %v", synced)
+	}
+
+	var objs []runtime.Object
+	for i := 0; i < 100; i++ {
+		if objs, err = informer.Lister().ByNamespace("ns1").List(labels.Everything()); err == nil && len(objs) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected 2 widgets, got %d (%v)", len(objs), err)
+	}
+	for _, obj := range objs {
+		if _, ok := obj.(*unstructured.Unstructured); !ok {
+			t.Errorf("expected *unstructured.Unstructured, got %T", obj)
+		}
+	}
+	obj, err := informer.Lister().ByNamespace("ns1").Get("w2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name := obj.(*unstructured.Unstructured).GetName(); name != "w2" {
+		t.Errorf("expected w2, got %s", name)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(listPaths) != 1 || listPaths[0] != "/apis/example.com/v1/namespaces/ns1/widgets" {
+		t.Errorf("unexpected list paths: %v", listPaths)
+	}
+	if len(selectors) != 1 || selectors[0] != "tier=frontend" {
+		t.Errorf("unexpected label selectors: %v", selectors)
+	}
+}
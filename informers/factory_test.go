@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/kubernetes/fake"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	core "github.com/lavalamp/client-go-flat/testing"
+)
+
+func TestSharedInformerFactorySharesInformers(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	factory := NewSharedInformerFactory(client, 0)
+
+	first := factory.Core().V1().Pods().Informer()
+	second := factory.Core().V1().Pods().Informer()
+	if first != second {
+		t.Errorf("expected the same informer for repeated requests of the same type")
+	}
+	if nodes := factory.Core().V1().Nodes().Informer(); nodes == first {
+		t.Errorf("expected distinct informers for distinct types")
+	}
+
+	generic, err := factory.ForResource(v1.SchemeGroupVersion.WithResource("pods"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if generic.Informer() != first {
+		t.Errorf("expected ForResource to return the shared pod informer")
+	}
+	if _, err := factory.ForResource(v1.SchemeGroupVersion.WithResource("widgets")); err == nil {
+		t.Errorf("expected an error for an unknown resource")
+	}
+}
+
+func TestSharedInformerFactoryStartAndSync(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", Labels: map[string]string{"app": "foo"}}}
+	client := fake.NewSimpleClientset(pod)
+
+	var lock sync.Mutex
+	var namespaces, selectors []string
+	client.PrependReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		namespaces = append(namespaces, action.GetNamespace())
+		selectors = append(selectors, action.(core.ListAction).GetListRestrictions().Labels.String())
+		return false, nil, nil
+	})
+
+	tweak := func(options *metav1.ListOptions) {
+		options.LabelSelector = "app=foo"
+	}
+	factory := NewFilteredSharedInformerFactory(client, time.Minute, "ns1", tweak)
+	podInformer := factory.Core().V1().Pods().Informer()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	// a second Start must not run the informer twice
+	factory.Start(stopCh)
+
+	synced := factory.WaitForCacheSync(stopCh)
+	if len(synced) != 1 || !synced[reflect.TypeOf(&v1.Pod{})] {
+		t.Fatalf("unexpected sync status: %v", synced)
+	}
+	// HasSynced can report true just before the last popped item is stored
+	err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return len(podInformer.GetStore().List()) == 1, nil
+	})
+	if err != nil {
+		t.Errorf("expected one pod in the cache, got %d", len(podInformer.GetStore().List()))
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if !reflect.DeepEqual(namespaces, []string{"ns1"}) {
+		t.Errorf("expected a single list in ns1, got %v", namespaces)
+	}
+	if !reflect.DeepEqual(selectors, []string{"app=foo"}) {
+		t.Errorf("expected tweaked label selector, got %v", selectors)
+	}
+}
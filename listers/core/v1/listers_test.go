@@ -0,0 +1,110 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/api/errors"
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/labels"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	"github.com/lavalamp/client-go-flat/tools/cache"
+)
+
+func newIndexer(objs ...interface{}) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		indexer.Add(obj)
+	}
+	return indexer
+}
+
+func TestPodLister(t *testing.T) {
+	lister := NewPodLister(newIndexer(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns1", Labels: map[string]string{"app": "foo"}}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns1"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns2", Labels: map[string]string{"app": "foo"}}},
+	))
+
+	all, err := lister.List(labels.Everything())
+	if err != nil || len(all) != 3 {
+		t.Errorf("expected 3 pods, got %d (%v)", len(all), err)
+	}
+	selected, err := lister.List(labels.SelectorFromSet(labels.Set{"app": "foo"}))
+	if err != nil || len(selected) != 2 {
+		t.Errorf("expected 2 selected pods, got %d (%v)", len(selected), err)
+	}
+	inNamespace, err := lister.Pods("ns1").List(labels.Everything())
+	if err != nil || len(inNamespace) != 2 {
+		t.Errorf("expected 2 pods in ns1, got %d (%v)", len(inNamespace), err)
+	}
+
+	pod, err := lister.Pods("ns2").Get("a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pod.Namespace != "ns2" || pod.Name != "a" {
+		t.Errorf("got the wrong pod: %s/%s", pod.Namespace, pod.Name)
+	}
+
+	_, err = lister.Pods("ns2").Get("b")
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestNodeListerGet(t *testing.T) {
+	lister := NewNodeLister(newIndexer(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}))
+
+	if _, err := lister.Get("node1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := lister.Get("node2"); !errors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestGetPodServices(t *testing.T) {
+	lister := NewServiceLister(newIndexer(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "match", Namespace: "ns1"},
+			Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "foo"}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-selector", Namespace: "ns1"},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "ns2"},
+			Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "foo"}},
+		},
+	))
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns1", Labels: map[string]string{"app": "foo"}}}
+	services, err := lister.GetPodServices(pod)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(services) != 1 || services[0].Name != "match" {
+		t.Errorf("expected only service match, got %v", services)
+	}
+
+	pod.Labels = map[string]string{"app": "bar"}
+	if _, err := lister.GetPodServices(pod); err == nil {
+		t.Errorf("expected an error when no services match")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	errors "github.com/lavalamp/client-go-flat/apimachinery/pkg/api/errors"
+	meta_v1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	labels "github.com/lavalamp/client-go-flat/apimachinery/pkg/labels"
+	v1beta1 "github.com/lavalamp/client-go-flat/pkg/apis/extensions/v1beta1"
+	cache "github.com/lavalamp/client-go-flat/tools/cache"
+)
+
+// ThirdPartyResourceLister helps list ThirdPartyResources.
+type ThirdPartyResourceLister interface {
+	// List lists all ThirdPartyResources in the indexer.
+	List(selector labels.Selector) (ret []*v1beta1.ThirdPartyResource, err error)
+	// Get retrieves the ThirdPartyResource from the index for a given name.
+	Get(name string) (*v1beta1.ThirdPartyResource, error)
+	ThirdPartyResourceListerExpansion
+}
+
+// thirdPartyResourceLister implements the ThirdPartyResourceLister interface.
+type thirdPartyResourceLister struct {
+	indexer cache.Indexer
+}
+
+// NewThirdPartyResourceLister returns a new ThirdPartyResourceLister.
+func NewThirdPartyResourceLister(indexer cache.Indexer) ThirdPartyResourceLister {
+	return &thirdPartyResourceLister{indexer: indexer}
+}
+
+// List lists all ThirdPartyResources in the indexer.
+func (s *thirdPartyResourceLister) List(selector labels.Selector) (ret []*v1beta1.ThirdPartyResource, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.ThirdPartyResource))
+	})
+	return ret, err
+}
+
+// Get retrieves the ThirdPartyResource from the index for a given name.
+func (s *thirdPartyResourceLister) Get(name string) (*v1beta1.ThirdPartyResource, error) {
+	key := &v1beta1.ThirdPartyResource{ObjectMeta: meta_v1.ObjectMeta{Name: name}}
+	obj, exists, err := s.indexer.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("thirdpartyresources"), name)
+	}
+	return obj.(*v1beta1.ThirdPartyResource), nil
+}
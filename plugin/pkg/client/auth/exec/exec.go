@@ -0,0 +1,385 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package exec implements credential plugins: external commands configured in
+// the exec stanza of a kubeconfig user, which print the credentials to use on
+// stdout.
+package exec
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "github.com/lavalamp/client-go-flat/tools/clientcmd/api"
+	"github.com/lavalamp/client-go-flat/transport"
+	"github.com/lavalamp/client-go-flat/util/connrotation"
+)
+
+const execInfoEnv = "KUBERNETES_EXEC_INFO"
+
+// ExecCredential is exchanged with a credential plugin. It is passed to the
+// plugin, without a status, in the KUBERNETES_EXEC_INFO environment variable,
+// and the plugin prints it back with the status filled in.
+type ExecCredential struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Spec holds information passed to the plugin by the transport.
+	Spec ExecCredentialSpec `json:"spec,omitempty"`
+
+	// Status is filled in by the plugin and holds the credentials that the
+	// transport should use to contact the API.
+	// +optional
+	Status *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// ExecCredentialSpec holds request and runtime specific information provided
+// by the transport.
+type ExecCredentialSpec struct {
+	// Response is populated when the transport encounters an HTTP response
+	// with a 401 status code, and is running the plugin to get new
+	// credentials.
+	// +optional
+	Response *Response `json:"response,omitempty"`
+}
+
+// ExecCredentialStatus holds credentials for the transport to use. Token and
+// ClientKeyData are sensitive fields and must not be logged.
+type ExecCredentialStatus struct {
+	// ExpirationTimestamp indicates a time when the provided credentials
+	// expire. If it is not set, the credentials are used until the server
+	// rejects them.
+	// +optional
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
+	// Token is a bearer token used by the client for request authentication.
+	Token string `json:"token,omitempty"`
+	// PEM-encoded client TLS certificate.
+	ClientCertificateData string `json:"clientCertificateData,omitempty"`
+	// PEM-encoded client TLS private key.
+	ClientKeyData string `json:"clientKeyData,omitempty"`
+}
+
+// Response defines metadata about a failed request, including HTTP status
+// code and response headers.
+type Response struct {
+	// Header holds HTTP headers returned by the server.
+	Header map[string][]string `json:"header,omitempty"`
+	// Code is the HTTP status code returned by the server.
+	Code int32 `json:"code,omitempty"`
+}
+
+// apiVersions are the versions of ExecCredential that can be exchanged with
+// plugins.
+var apiVersions = map[string]bool{
+	"client.authentication.k8s.io/v1alpha1": true,
+}
+
+// cache holds the authenticators of each configuration, so that all the
+// clients of a process share the credentials of a plugin.
+var cache = struct {
+	lock           sync.Mutex
+	authenticators []*Authenticator
+}{}
+
+// GetAuthenticator returns an exec-based plugin for providing client
+// credentials. The same authenticator is returned for identical
+// configurations.
+func GetAuthenticator(config *clientcmdapi.ExecConfig) (*Authenticator, error) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	for _, a := range cache.authenticators {
+		if reflect.DeepEqual(a.config, *config) {
+			return a, nil
+		}
+	}
+	a, err := newAuthenticator(config)
+	if err != nil {
+		return nil, err
+	}
+	cache.authenticators = append(cache.authenticators, a)
+	return a, nil
+}
+
+func newAuthenticator(config *clientcmdapi.ExecConfig) (*Authenticator, error) {
+	if !apiVersions[config.APIVersion] {
+		return nil, fmt.Errorf("exec plugin: invalid apiVersion %q", config.APIVersion)
+	}
+
+	a := &Authenticator{
+		config:  *config,
+		cmd:     config.Command,
+		args:    config.Args,
+		stdin:   os.Stdin,
+		stderr:  os.Stderr,
+		now:     time.Now,
+		environ: os.Environ,
+	}
+	for _, env := range config.Env {
+		a.env = append(a.env, env.Name+"="+env.Value)
+	}
+	a.getCert = &transport.GetCertHolder{GetCert: a.cert}
+	a.connTracker = connrotation.NewDialer((&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).Dial)
+	a.dial = &transport.DialHolder{Dial: a.connTracker.Dial}
+	return a, nil
+}
+
+// Authenticator is a client credential provider that rotates credentials by
+// executing a plugin. The plugin input and output are defined by the
+// ExecCredential type.
+type Authenticator struct {
+	// Set by the config
+	config clientcmdapi.ExecConfig
+	cmd    string
+	args   []string
+	env    []string
+
+	// getCert identifies the certificate callback of the authenticator, so
+	// that its clients share a cached transport
+	getCert *transport.GetCertHolder
+
+	// connTracker tracks the connections of the clients of the
+	// authenticator, which present the client certificate, so that they can
+	// be closed when the plugin returns a new one. dial identifies its Dial.
+	connTracker *connrotation.Dialer
+	dial        *transport.DialHolder
+
+	// Stubbable for testing
+	stdin   io.Reader
+	stderr  io.Writer
+	now     func() time.Time
+	environ func() []string
+
+	// mu guards the cached credentials and their expiry, and serializes
+	// runs of the plugin.
+	mu          sync.Mutex
+	cachedCreds *credentials
+	exp         time.Time
+}
+
+type credentials struct {
+	token string
+	cert  *tls.Certificate
+}
+
+// UpdateTransportConfig updates the transport.Config to use credentials
+// returned by the plugin: a round tripper sets the bearer token and runs the
+// plugin again when the server rejects it, and the TLS configuration asks
+// the plugin for the client certificate. Connections are dialed by the
+// authenticator, with a keep-alive period of 30 seconds, so that they can be
+// closed when the client certificate changes.
+func (a *Authenticator) UpdateTransportConfig(c *transport.Config) error {
+	wt := c.WrapTransport
+	c.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wt != nil {
+			rt = wt(rt)
+		}
+		return &roundTripper{a, rt}
+	}
+
+	if c.TLS.GetCert != nil {
+		return fmt.Errorf("can't add TLS certificate callback: transport.Config.TLS.GetCert already set")
+	}
+	c.TLS.GetCert = a.getCert.GetCert
+	c.TLS.GetCertHolder = a.getCert
+
+	if c.Dial != nil {
+		return fmt.Errorf("can't add dial func: transport.Config.Dial already set")
+	}
+	c.Dial = a.dial.Dial
+	c.DialHolder = a.dial
+	return nil
+}
+
+type roundTripper struct {
+	a    *Authenticator
+	base http.RoundTripper
+}
+
+func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// If a user has already set credentials, use that. This makes commands like
+	// "kubectl get --token (token) pods" work.
+	if req.Header.Get("Authorization") != "" {
+		return r.base.RoundTrip(req)
+	}
+
+	creds, err := r.a.getCreds()
+	if err != nil {
+		return nil, fmt.Errorf("getting credentials: %v", err)
+	}
+	if creds.token != "" {
+		req = cloneRequest(req)
+		req.Header.Set("Authorization", "Bearer "+creds.token)
+	}
+
+	res, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		resp := &Response{
+			Header: res.Header,
+			Code:   int32(res.StatusCode),
+		}
+		if err := r.a.maybeRefreshCreds(creds, resp); err != nil {
+			glog.Errorf("refreshing credentials: %v", err)
+		}
+	}
+	return res, nil
+}
+
+func (r *roundTripper) WrappedRoundTripper() http.RoundTripper { return r.base }
+
+// cloneRequest returns a shallow copy of req with a deep copy of its headers.
+func cloneRequest(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	return r
+}
+
+func (a *Authenticator) credsExpired() bool {
+	if a.exp.IsZero() {
+		return false
+	}
+	return a.now().After(a.exp)
+}
+
+func (a *Authenticator) cert() (*tls.Certificate, error) {
+	creds, err := a.getCreds()
+	if err != nil {
+		return nil, err
+	}
+	return creds.cert, nil
+}
+
+func (a *Authenticator) getCreds() (*credentials, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cachedCreds != nil && !a.credsExpired() {
+		return a.cachedCreds, nil
+	}
+
+	if err := a.refreshCredsLocked(nil); err != nil {
+		return nil, err
+	}
+	return a.cachedCreds, nil
+}
+
+// maybeRefreshCreds executes the plugin to force a rotation of the
+// credentials, unless they were already rotated since creds were handed out.
+func (a *Authenticator) maybeRefreshCreds(creds *credentials, r *Response) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Since we're not making a new pointer to a.cachedCreds in getCreds, no
+	// need to do deep comparison.
+	if creds != a.cachedCreds {
+		// Credentials already rotated.
+		return nil
+	}
+
+	return a.refreshCredsLocked(r)
+}
+
+// refreshCredsLocked executes the plugin and reads the credentials from
+// stdout. It must be called while holding the Authenticator's mutex.
+func (a *Authenticator) refreshCredsLocked(r *Response) error {
+	cred := &ExecCredential{
+		TypeMeta: metav1.TypeMeta{APIVersion: a.config.APIVersion, Kind: "ExecCredential"},
+		Spec:     ExecCredentialSpec{Response: r},
+	}
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return fmt.Errorf("encode ExecCredential: %v", err)
+	}
+
+	env := append(a.environ(), a.env...)
+	env = append(env, fmt.Sprintf("%s=%s", execInfoEnv, data))
+
+	stdout := &bytes.Buffer{}
+	cmd := exec.Command(a.cmd, a.args...)
+	cmd.Env = env
+	cmd.Stderr = a.stderr
+	cmd.Stdout = stdout
+	cmd.Stdin = a.stdin
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("exec: %v", err)
+	}
+
+	cred = &ExecCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), cred); err != nil {
+		return fmt.Errorf("decoding stdout: %v", err)
+	}
+	if cred.APIVersion != a.config.APIVersion {
+		return fmt.Errorf("exec plugin is configured to use API version %s, plugin returned version %s",
+			a.config.APIVersion, cred.APIVersion)
+	}
+	if cred.Kind != "ExecCredential" {
+		return fmt.Errorf("exec plugin returned kind %q, expected ExecCredential", cred.Kind)
+	}
+	if cred.Status == nil {
+		return fmt.Errorf("exec plugin didn't return a status field")
+	}
+	status := cred.Status
+	if status.Token == "" && status.ClientCertificateData == "" && status.ClientKeyData == "" {
+		return fmt.Errorf("exec plugin didn't return a token or cert/key pair")
+	}
+	if (status.ClientCertificateData == "") != (status.ClientKeyData == "") {
+		return fmt.Errorf("exec plugin returned only certificate or key, not both")
+	}
+
+	newCreds := &credentials{token: status.Token}
+	if status.ClientKeyData != "" && status.ClientCertificateData != "" {
+		cert, err := tls.X509KeyPair([]byte(status.ClientCertificateData), []byte(status.ClientKeyData))
+		if err != nil {
+			return fmt.Errorf("failed parsing client key/certificate: %v", err)
+		}
+		newCreds.cert = &cert
+	}
+
+	if status.ExpirationTimestamp != nil {
+		a.exp = status.ExpirationTimestamp.Time
+	} else {
+		a.exp = time.Time{}
+	}
+	oldCreds := a.cachedCreds
+	a.cachedCreds = newCreds
+	// A connection presents the client certificate only when it's
+	// established, so close the connections using the previous one.
+	if oldCreds != nil && !reflect.DeepEqual(oldCreds.cert, newCreds.cert) {
+		glog.V(2).Infof("exec plugin: client certificate changed, closing all connections")
+		a.connTracker.CloseAll()
+	}
+	return nil
+}
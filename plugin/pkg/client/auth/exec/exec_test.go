@@ -0,0 +1,388 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	clientcmdapi "github.com/lavalamp/client-go-flat/tools/clientcmd/api"
+	"github.com/lavalamp/client-go-flat/transport"
+	certutil "github.com/lavalamp/client-go-flat/util/cert"
+)
+
+const apiVersion = "client.authentication.k8s.io/v1alpha1"
+
+// testPlugin is a shell plugin that logs the ExecCredential it is given, one
+// per line, and prints the output configured by the test.
+type testPlugin struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestPlugin(t *testing.T) *testPlugin {
+	dir, err := ioutil.TempDir("", "exec-plugin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &testPlugin{t, dir}
+}
+
+func (p *testPlugin) authenticator(output string) *Authenticator {
+	if err := ioutil.WriteFile(filepath.Join(p.dir, "output"), []byte(output), 0600); err != nil {
+		p.t.Fatalf("unexpected error: %v", err)
+	}
+	a, err := newAuthenticator(&clientcmdapi.ExecConfig{
+		Command:    "sh",
+		Args:       []string{"-c", `echo "$KUBERNETES_EXEC_INFO" >> "$DIR/calls"; cat "$DIR/output"`},
+		Env:        []clientcmdapi.ExecEnvVar{{Name: "DIR", Value: p.dir}},
+		APIVersion: apiVersion,
+	})
+	if err != nil {
+		p.t.Fatalf("unexpected error: %v", err)
+	}
+	a.environ = func() []string { return nil }
+	a.stderr = ioutil.Discard
+	return a
+}
+
+func (p *testPlugin) setOutput(output string) {
+	if err := ioutil.WriteFile(filepath.Join(p.dir, "output"), []byte(output), 0600); err != nil {
+		p.t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// calls returns the ExecCredential the plugin was run with each time.
+func (p *testPlugin) calls() []ExecCredential {
+	data, err := ioutil.ReadFile(filepath.Join(p.dir, "calls"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		p.t.Fatalf("unexpected error: %v", err)
+	}
+	var calls []ExecCredential
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var cred ExecCredential
+		if err := json.Unmarshal([]byte(line), &cred); err != nil {
+			p.t.Fatalf("unexpected error decoding %q: %v", line, err)
+		}
+		calls = append(calls, cred)
+	}
+	return calls
+}
+
+func (p *testPlugin) cleanup() {
+	os.RemoveAll(p.dir)
+}
+
+func tokenOutput(token string, expiry string) string {
+	status := fmt.Sprintf(`"token": %q`, token)
+	if len(expiry) > 0 {
+		status += fmt.Sprintf(`, "expirationTimestamp": %q`, expiry)
+	}
+	return fmt.Sprintf(`{"kind": "ExecCredential", "apiVersion": %q, "status": {%s}}`, apiVersion, status)
+}
+
+func TestRefreshCreds(t *testing.T) {
+	certData, keyData, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		output    string
+		wantToken string
+		wantCert  bool
+		wantErr   string
+	}{
+		{
+			name:      "token",
+			output:    tokenOutput("foo-bar", ""),
+			wantToken: "foo-bar",
+		},
+		{
+			name: "cert and key",
+			output: fmt.Sprintf(`{"kind": "ExecCredential", "apiVersion": %q, "status": {"clientCertificateData": %q, "clientKeyData": %q}}`,
+				apiVersion, certData, keyData),
+			wantCert: true,
+		},
+		{
+			name:    "wrong version",
+			output:  `{"kind": "ExecCredential", "apiVersion": "client.authentication.k8s.io/v1", "status": {"token": "foo-bar"}}`,
+			wantErr: "plugin returned version client.authentication.k8s.io/v1",
+		},
+		{
+			name:    "wrong kind",
+			output:  fmt.Sprintf(`{"kind": "Status", "apiVersion": %q, "status": {"token": "foo-bar"}}`, apiVersion),
+			wantErr: `returned kind "Status"`,
+		},
+		{
+			name:    "no status",
+			output:  fmt.Sprintf(`{"kind": "ExecCredential", "apiVersion": %q}`, apiVersion),
+			wantErr: "didn't return a status field",
+		},
+		{
+			name:    "no credentials",
+			output:  fmt.Sprintf(`{"kind": "ExecCredential", "apiVersion": %q, "status": {}}`, apiVersion),
+			wantErr: "didn't return a token or cert/key pair",
+		},
+		{
+			name:    "cert without key",
+			output:  fmt.Sprintf(`{"kind": "ExecCredential", "apiVersion": %q, "status": {"clientCertificateData": %q}}`, apiVersion, certData),
+			wantErr: "only certificate or key",
+		},
+		{
+			name:    "not json",
+			output:  "foo-bar",
+			wantErr: "decoding stdout",
+		},
+	}
+	for _, test := range tests {
+		p := newTestPlugin(t)
+		a := p.authenticator(test.output)
+		creds, err := a.getCreds()
+		p.cleanup()
+		if len(test.wantErr) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", test.name, test.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if creds.token != test.wantToken || (creds.cert != nil) != test.wantCert {
+			t.Errorf("%s: unexpected credentials %#v", test.name, creds)
+		}
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	p := newTestPlugin(t)
+	defer p.cleanup()
+	a := p.authenticator(tokenOutput("token1", "2017-08-01T12:00:00Z"))
+	now := time.Date(2017, 8, 1, 11, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	var authorizations []string
+	status := http.StatusOK
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		authorizations = append(authorizations, req.Header.Get("Authorization"))
+		return &http.Response{StatusCode: status, Header: http.Header{"Www-Authenticate": []string{"Bearer"}}}, nil
+	})
+	c := &transport.Config{}
+	if err := a.UpdateTransportConfig(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rt := c.WrapTransport(base)
+	get := func() {
+		req, _ := http.NewRequest("GET", "https://localhost", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the token is cached until it expires
+	get()
+	get()
+	p.setOutput(tokenOutput("token2", ""))
+	now = now.Add(2 * time.Hour)
+	get()
+	// and until the server rejects it
+	status = http.StatusUnauthorized
+	p.setOutput(tokenOutput("token3", ""))
+	get()
+	status = http.StatusOK
+	get()
+
+	expected := []string{"Bearer token1", "Bearer token1", "Bearer token2", "Bearer token2", "Bearer token3"}
+	if strings.Join(authorizations, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected authorizations %v, got %v", expected, authorizations)
+	}
+	calls := p.calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected the plugin to run 3 times, got %#v", calls)
+	}
+	if calls[0].APIVersion != apiVersion || calls[0].Kind != "ExecCredential" || calls[0].Spec.Response != nil {
+		t.Errorf("unexpected input for the first run: %#v", calls[0])
+	}
+	if response := calls[2].Spec.Response; response == nil || response.Code != http.StatusUnauthorized || http.Header(response.Header).Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("expected the rejected response to be passed to the plugin, got %#v", response)
+	}
+
+	// credentials set by the caller are left alone
+	req, _ := http.NewRequest("GET", "https://localhost", nil)
+	req.Header.Set("Authorization", "Bearer mine")
+	rt.RoundTrip(req)
+	if last := authorizations[len(authorizations)-1]; last != "Bearer mine" {
+		t.Errorf("expected the caller's credentials to be used, got %q", last)
+	}
+}
+
+func certOutput(t *testing.T, host, expiry string) string {
+	certData, keyData, err := certutil.GenerateSelfSignedCertKey(host, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return fmt.Sprintf(`{"kind": "ExecCredential", "apiVersion": %q, "status": {"clientCertificateData": %q, "clientKeyData": %q, "expirationTimestamp": %q}}`,
+		apiVersion, certData, keyData, expiry)
+}
+
+func TestCertRotation(t *testing.T) {
+	p := newTestPlugin(t)
+	defer p.cleanup()
+	a := p.authenticator(certOutput(t, "first", "2017-08-01T12:00:00Z"))
+	now := time.Date(2017, 8, 1, 11, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	serverCertData, serverKeyData, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serverCert, err := tls.X509KeyPair(serverCertData, serverKeyData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lock sync.Mutex
+	var clients []string
+	server := &http.Server{
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAnyClientCert,
+		},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			clients = append(clients, req.URL.Path+" "+strings.Split(req.TLS.PeerCertificates[0].Subject.CommonName, "@")[0])
+			lock.Unlock()
+			if req.URL.Path == "/watch" {
+				// stream until the client goes away, like a watch
+				w.(http.Flusher).Flush()
+				<-req.Context().Done()
+			}
+		}),
+	}
+	// serving TLS enables HTTP/2, which the transport uses by default
+	go server.ServeTLS(listener, "", "")
+	defer server.Close()
+
+	c := &transport.Config{TLS: transport.TLSConfig{Insecure: true}}
+	if err := a.UpdateTransportConfig(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rt, err := transport.New(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: rt}
+	get := func(path string) *http.Response {
+		resp, err := client.Get("https://" + listener.Addr().String() + path)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", path, err)
+		}
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("expected an HTTP/2 connection, got %s", resp.Proto)
+		}
+		return resp
+	}
+
+	resp := get("/")
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	watch := get("/watch")
+	defer watch.Body.Close()
+	watchEnded := make(chan error, 1)
+	go func() {
+		_, err := ioutil.ReadAll(watch.Body)
+		watchEnded <- err
+	}()
+
+	// the plugin is run again once the certificate expires
+	p.setOutput(certOutput(t, "second", "2017-08-01T20:00:00Z"))
+	now = now.Add(2 * time.Hour)
+	resp = get("/")
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	select {
+	case <-watchEnded:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the watch on the connection presenting the previous certificate to be closed")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if expected := []string{"/ first", "/watch first", "/ second"}; strings.Join(clients, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected client certificates %v, got %v", expected, clients)
+	}
+}
+
+func TestGetAuthenticatorIsShared(t *testing.T) {
+	config := &clientcmdapi.ExecConfig{Command: "true", APIVersion: apiVersion}
+	a, err := GetAuthenticator(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := GetAuthenticator(&clientcmdapi.ExecConfig{Command: "true", APIVersion: apiVersion})
+	if err != nil || a != b {
+		t.Errorf("expected identical configs to share an authenticator, got %p and %p: %v", a, b, err)
+	}
+	if c, _ := GetAuthenticator(&clientcmdapi.ExecConfig{Command: "true", Args: []string{"x"}, APIVersion: apiVersion}); c == a {
+		t.Errorf("expected different configs not to share an authenticator")
+	}
+
+	// clients of the same authenticator share a cached transport
+	configA, configB := &transport.Config{}, &transport.Config{}
+	if err := a.UpdateTransportConfig(configA); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := b.UpdateTransportConfig(configB); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if configA.TLS.GetCertHolder == nil || configA.TLS.GetCertHolder != configB.TLS.GetCertHolder {
+		t.Errorf("expected the certificate callbacks to have the same holder, got %p and %p", configA.TLS.GetCertHolder, configB.TLS.GetCertHolder)
+	}
+	if configA.DialHolder == nil || configA.DialHolder != configB.DialHolder {
+		t.Errorf("expected the dial funcs to have the same holder, got %p and %p", configA.DialHolder, configB.DialHolder)
+	}
+	if _, err := GetAuthenticator(&clientcmdapi.ExecConfig{Command: "true", APIVersion: "v1"}); err == nil {
+		t.Errorf("expected an error for an unknown apiVersion")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime/schema"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/types"
+	"github.com/lavalamp/client-go-flat/transport"
 	"github.com/lavalamp/client-go-flat/util/flowcontrol"
 )
 
@@ -76,6 +77,24 @@ type RESTClient struct {
 	// TODO extract this into a wrapper interface via the RESTClient interface in kubectl.
 	Throttle flowcontrol.RateLimiter
 
+	// RateLimitClassifier, if set, assigns requests to rate limit classes.  Requests of a
+	// class in ClassRateLimiters are throttled by its rate limiter instead of Throttle.
+	RateLimitClassifier RateLimitClassifier
+	ClassRateLimiters   map[string]flowcontrol.RateLimiter
+
+	// RetryPolicy is passed to requests to decide whether failed attempts are retried.
+	// If not set DefaultRetryPolicy will be used.
+	RetryPolicy RetryPolicy
+
+	// Tracer, if set, is used by requests to start a span for every call to the server.
+	Tracer transport.Tracer
+
+	// WatchIdleTimeout, if set, closes watches that received no data for that long.
+	WatchIdleTimeout time.Duration
+
+	// endpoints, if set, are the apiservers requests fail over between.
+	endpoints *endpoints
+
 	// Set specific behavior of the client.  If not set http.DefaultClient will be used.
 	Client *http.Client
 }
@@ -93,11 +112,7 @@ type Serializers struct {
 // decoding of responses from the server.
 func NewRESTClient(baseURL *url.URL, versionedAPIPath string, config ContentConfig, maxQPS float32, maxBurst int, rateLimiter flowcontrol.RateLimiter, client *http.Client) (*RESTClient, error) {
 	base := *baseURL
-	if !strings.HasSuffix(base.Path, "/") {
-		base.Path += "/"
-	}
-	base.RawQuery = ""
-	base.Fragment = ""
+	normalizeBaseURL(&base)
 
 	if config.GroupVersion == nil {
 		config.GroupVersion = &schema.GroupVersion{}
@@ -127,6 +142,15 @@ func NewRESTClient(baseURL *url.URL, versionedAPIPath string, config ContentConf
 	}, nil
 }
 
+// normalizeBaseURL makes base end in a slash and drops its query and fragment.
+func normalizeBaseURL(base *url.URL) {
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	base.RawQuery = ""
+	base.Fragment = ""
+}
+
 // GetRateLimiter returns rate limier for a given client, or nil if it's called on a nil client
 func (c *RESTClient) GetRateLimiter() flowcontrol.RateLimiter {
 	if c == nil {
@@ -221,10 +245,18 @@ func createSerializers(config ContentConfig) (*Serializers, error) {
 func (c *RESTClient) Verb(verb string) *Request {
 	backoff := c.createBackoffMgr()
 
+	var request *Request
 	if c.Client == nil {
-		return NewRequest(nil, verb, c.base, c.versionedAPIPath, c.contentConfig, c.serializers, backoff, c.Throttle)
+		request = NewRequest(nil, verb, c.base, c.versionedAPIPath, c.contentConfig, c.serializers, backoff, c.Throttle)
+	} else {
+		request = NewRequest(c.Client, verb, c.base, c.versionedAPIPath, c.contentConfig, c.serializers, backoff, c.Throttle)
 	}
-	return NewRequest(c.Client, verb, c.base, c.versionedAPIPath, c.contentConfig, c.serializers, backoff, c.Throttle)
+	request.tracer = c.Tracer
+	request.watchIdleTimeout = c.WatchIdleTimeout
+	request.endpoints = c.endpoints
+	request.rateLimitClassifier = c.RateLimitClassifier
+	request.classRateLimiters = c.ClassRateLimiters
+	return request.RetryPolicy(c.RetryPolicy)
 }
 
 // Post begins a POST request. Short for c.Verb("POST").
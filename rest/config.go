@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	gruntime "runtime"
@@ -35,6 +36,7 @@ import (
 	"github.com/lavalamp/client-go-flat/pkg/api"
 	"github.com/lavalamp/client-go-flat/pkg/version"
 	clientcmdapi "github.com/lavalamp/client-go-flat/tools/clientcmd/api"
+	"github.com/lavalamp/client-go-flat/transport"
 	certutil "github.com/lavalamp/client-go-flat/util/cert"
 	"github.com/lavalamp/client-go-flat/util/flowcontrol"
 )
@@ -52,6 +54,15 @@ type Config struct {
 	// be appended to all request URIs used to access the apiserver. This allows a frontend
 	// proxy to easily relocate all of the apiserver endpoints.
 	Host string
+	// Endpoints lists further apiservers of the same cluster as Host, in the same form and
+	// with the same scheme and path. Requests go to the first of Host and Endpoints that is
+	// healthy, and fail over to the next one when an endpoint cannot be reached or answers
+	// as overloaded. All endpoints share the TLS configuration, including the CA, and the
+	// credentials of this Config.
+	Endpoints []string
+	// RandomizeEndpoints shuffles the order of Host and Endpoints for every client, so that
+	// clients spread over the apiservers instead of all preferring Host.
+	RandomizeEndpoints bool
 	// APIPath is a sub-path that points to an API root.
 	APIPath string
 	// Prefix is the sub path of the server. If not specified, the client will set
@@ -71,6 +82,11 @@ type Config struct {
 	// TODO: demonstrate an OAuth2 compatible client.
 	BearerToken string
 
+	// Path to a file containing a BearerToken. The file is read again when it
+	// changes, so rotated tokens are used without recreating the client. If
+	// set, the token from the file takes precedence over BearerToken.
+	BearerTokenFile string
+
 	// Impersonate is the configuration that RESTClient will use for impersonation.
 	Impersonate ImpersonationConfig
 
@@ -80,6 +96,9 @@ type Config struct {
 	// Callback to persist config for AuthProvider.
 	AuthConfigPersister AuthProviderConfigPersister
 
+	// Exec-based authentication provider.
+	ExecProvider *clientcmdapi.ExecConfig
+
 	// TLSClientConfig contains settings to enable transport layer security
 	TLSClientConfig
 
@@ -111,9 +130,78 @@ type Config struct {
 	// Rate limiter for limiting connections to the master from this client. If present overwrites QPS/Burst
 	RateLimiter flowcontrol.RateLimiter
 
+	// AdaptiveRateLimit, if set and RateLimiter is not, replaces the fixed QPS with a rate
+	// limiter that slows down when the server throttles requests or responds slowly, and
+	// speeds up again while it keeps up. Its InitialQPS and MaxQPS default to QPS, and its
+	// Burst to Burst.
+	AdaptiveRateLimit *flowcontrol.AdaptiveRateLimiterConfig
+
+	// RateLimitClassifier, if set, assigns every request to a rate limit class, by its verb,
+	// resource and subresource. Requests of a class listed in ClassRateLimiters are throttled
+	// by the rate limiter of their class, so that they neither wait for nor hold up requests
+	// of other classes. Requests of other classes are throttled as if there were no
+	// classifier. Watches, which are classified with the verb WATCH, are only throttled by a
+	// rate limiter of their own class.
+	RateLimitClassifier RateLimitClassifier
+
+	// ClassRateLimiters holds the rate limiters of the classes of RateLimitClassifier, by
+	// class name.
+	ClassRateLimiters map[string]flowcontrol.RateLimiter
+
 	// The maximum length of time to wait before giving up on a server request. A value of zero means no timeout.
 	Timeout time.Duration
 
+	// WatchIdleTimeout is the longest a watch may go without receiving any data from the server.
+	// Past that, the connection is assumed to be dead: the watch is closed and reports an error,
+	// so that its consumer watches again. A value of zero means no timeout.
+	//
+	// The server sends nothing on a watch while the watched resources don't change: there are no
+	// bookmarks or heartbeats. A watch of a quiet resource can't be told apart from a dead
+	// connection, and is closed and watched again every WatchIdleTimeout. Use a value above the
+	// timeout the watches ask the server for, such as more than the 10 minutes of the reflectors
+	// of informers, so that quiet watches end normally before they are considered dead.
+	WatchIdleTimeout time.Duration
+
+	// Proxy is the proxy func to be used for all requests made by this transport. If Proxy is
+	// nil, http.ProxyFromEnvironment is used. If Proxy returns a nil *URL, no proxy is used. The
+	// http, https and socks5 proxy schemes are supported.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// ProxyURL, if set and Proxy is not, is the proxy all requests are sent through. Unlike
+	// Proxy, clients with the same ProxyURL share their connections.
+	ProxyURL *url.URL
+
+	// TCPKeepAlive is the keep-alive period of the connections to the server. If zero, a default
+	// of 30 seconds is used. A negative value disables keep-alives.
+	TCPKeepAlive time.Duration
+
+	// ReadIdleTimeout, if set, health checks the HTTP/2 connections to the server: a PING frame
+	// is sent on a connection that received no frame for that long, and the connection is closed
+	// if the PING isn't answered within PingTimeout. This detects dead connections, such as ones
+	// silently dropped by a load balancer, faster than TCPKeepAlive.
+	ReadIdleTimeout time.Duration
+
+	// PingTimeout is how long a health check PING waits for its answer before the connection is
+	// closed. If zero, a default of 15 seconds is used.
+	PingTimeout time.Duration
+
+	// RetryPolicy decides whether requests are sent again after an attempt failed. If it is nil,
+	// DefaultRetryPolicy is used, which only retries 429 and 5xx responses with a Retry-After
+	// header and GETs whose connection was reset. Set TransientErrorRetryPolicy to also retry the
+	// other connection errors of idempotent requests, such as those of an unreachable server.
+	RetryPolicy RetryPolicy
+
+	// Tracer, if set, starts a span for every request and watch, carrying attributes such as the
+	// verb, resource, namespace, status code and number of retries, and a child span for every
+	// attempt sent to the server. The span of each attempt is propagated to the server in the W3C
+	// traceparent header.
+	Tracer transport.Tracer
+
+	// RequestLogger, if set, logs every request sent to the server and its response, including
+	// the headers and optionally the start of the bodies, to a sink such as a file of JSON lines.
+	// The values of the Authorization and Impersonate-* headers are redacted by default.
+	RequestLogger *transport.RequestLoggerConfig
+
 	// Version forces a specific version to be used (if registered)
 	// Do we need this?
 	// Version string
@@ -206,7 +294,20 @@ func RESTClientFor(config *Config) (*RESTClient, error) {
 		}
 	}
 
-	return NewRESTClient(baseURL, versionedAPIPath, config.ContentConfig, qps, burst, config.RateLimiter, httpClient)
+	restClient, err := NewRESTClient(baseURL, versionedAPIPath, config.ContentConfig, qps, burst, rateLimiterFor(config, qps, burst), httpClient)
+	if err != nil {
+		return nil, err
+	}
+	restClient.RetryPolicy = config.RetryPolicy
+	restClient.Tracer = config.Tracer
+	restClient.WatchIdleTimeout = config.WatchIdleTimeout
+	restClient.RateLimitClassifier = config.RateLimitClassifier
+	restClient.ClassRateLimiters = config.ClassRateLimiters
+	restClient.endpoints, err = endpointsFor(config, restClient.base)
+	if err != nil {
+		return nil, err
+	}
+	return restClient, nil
 }
 
 // UnversionedRESTClientFor is the same as RESTClientFor, except that it allows
@@ -240,7 +341,39 @@ func UnversionedRESTClientFor(config *Config) (*RESTClient, error) {
 		versionConfig.GroupVersion = &v
 	}
 
-	return NewRESTClient(baseURL, versionedAPIPath, versionConfig, config.QPS, config.Burst, config.RateLimiter, httpClient)
+	restClient, err := NewRESTClient(baseURL, versionedAPIPath, versionConfig, config.QPS, config.Burst, rateLimiterFor(config, config.QPS, config.Burst), httpClient)
+	if err != nil {
+		return nil, err
+	}
+	restClient.RetryPolicy = config.RetryPolicy
+	restClient.Tracer = config.Tracer
+	restClient.WatchIdleTimeout = config.WatchIdleTimeout
+	restClient.RateLimitClassifier = config.RateLimitClassifier
+	restClient.ClassRateLimiters = config.ClassRateLimiters
+	restClient.endpoints, err = endpointsFor(config, restClient.base)
+	if err != nil {
+		return nil, err
+	}
+	return restClient, nil
+}
+
+// rateLimiterFor returns the rate limiter of config, or an adaptive one starting at qps
+// and burst if config asks for it. It returns nil if the client should use a fixed QPS.
+func rateLimiterFor(config *Config, qps float32, burst int) flowcontrol.RateLimiter {
+	if config.RateLimiter != nil || config.AdaptiveRateLimit == nil {
+		return config.RateLimiter
+	}
+	adaptive := *config.AdaptiveRateLimit
+	if adaptive.InitialQPS == 0 {
+		adaptive.InitialQPS = qps
+	}
+	if adaptive.MaxQPS == 0 {
+		adaptive.MaxQPS = qps
+	}
+	if adaptive.Burst == 0 {
+		adaptive.Burst = burst
+	}
+	return flowcontrol.NewAdaptiveRateLimiter(adaptive)
 }
 
 // SetKubernetesDefaults sets default values on the provided client config for accessing the
@@ -277,7 +410,8 @@ func InClusterConfig() (*Config, error) {
 		return nil, fmt.Errorf("unable to load in-cluster configuration, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined")
 	}
 
-	token, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/" + api.ServiceAccountTokenKey)
+	tokenFile := "/var/run/secrets/kubernetes.io/serviceaccount/" + api.ServiceAccountTokenKey
+	token, err := ioutil.ReadFile(tokenFile)
 	if err != nil {
 		return nil, err
 	}
@@ -293,6 +427,7 @@ func InClusterConfig() (*Config, error) {
 		// TODO: switch to using cluster DNS.
 		Host:            "https://" + net.JoinHostPort(host, port),
 		BearerToken:     string(token),
+		BearerTokenFile: tokenFile,
 		TLSClientConfig: tlsClientConfig,
 	}, nil
 }
@@ -360,21 +495,35 @@ func AddUserAgent(config *Config, userAgent string) *Config {
 func AnonymousClientConfig(config *Config) *Config {
 	// copy only known safe fields
 	return &Config{
-		Host:          config.Host,
-		APIPath:       config.APIPath,
-		Prefix:        config.Prefix,
-		ContentConfig: config.ContentConfig,
+		Host:               config.Host,
+		Endpoints:          config.Endpoints,
+		RandomizeEndpoints: config.RandomizeEndpoints,
+		APIPath:            config.APIPath,
+		Prefix:             config.Prefix,
+		ContentConfig:      config.ContentConfig,
 		TLSClientConfig: TLSClientConfig{
 			CAFile: config.TLSClientConfig.CAFile,
 			CAData: config.TLSClientConfig.CAData,
 		},
-		RateLimiter:   config.RateLimiter,
-		Insecure:      config.Insecure,
-		UserAgent:     config.UserAgent,
-		Transport:     config.Transport,
-		WrapTransport: config.WrapTransport,
-		QPS:           config.QPS,
-		Burst:         config.Burst,
-		Timeout:       config.Timeout,
+		RateLimiter:         config.RateLimiter,
+		AdaptiveRateLimit:   config.AdaptiveRateLimit,
+		RateLimitClassifier: config.RateLimitClassifier,
+		ClassRateLimiters:   config.ClassRateLimiters,
+		Insecure:            config.Insecure,
+		UserAgent:           config.UserAgent,
+		Transport:           config.Transport,
+		WrapTransport:       config.WrapTransport,
+		QPS:                 config.QPS,
+		Burst:               config.Burst,
+		Timeout:             config.Timeout,
+		WatchIdleTimeout:    config.WatchIdleTimeout,
+		TCPKeepAlive:        config.TCPKeepAlive,
+		ReadIdleTimeout:     config.ReadIdleTimeout,
+		PingTimeout:         config.PingTimeout,
+		Proxy:               config.Proxy,
+		ProxyURL:            config.ProxyURL,
+		RetryPolicy:         config.RetryPolicy,
+		Tracer:              config.Tracer,
+		RequestLogger:       config.RequestLogger,
 	}
 }
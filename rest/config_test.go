@@ -18,7 +18,9 @@ package rest
 
 import (
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -30,6 +32,7 @@ import (
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/diff"
 	"github.com/lavalamp/client-go-flat/pkg/api"
 	clientcmdapi "github.com/lavalamp/client-go-flat/tools/clientcmd/api"
+	"github.com/lavalamp/client-go-flat/transport"
 	"github.com/lavalamp/client-go-flat/util/flowcontrol"
 
 	_ "github.com/lavalamp/client-go-flat/pkg/api/install"
@@ -131,6 +134,14 @@ func (t *fakeLimiter) Stop() {}
 
 func (t *fakeLimiter) Accept() {}
 
+type fakeClassifier struct {
+	Class string
+}
+
+func (c *fakeClassifier) Classify(verb, resource, subresource string) string {
+	return c.Class
+}
+
 type fakeCodec struct{}
 
 func (c *fakeCodec) Decode([]byte, *schema.GroupVersionKind, runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
@@ -151,6 +162,10 @@ var fakeWrapperFunc = func(http.RoundTripper) http.RoundTripper {
 	return &fakeRoundTripper{}
 }
 
+var fakeProxyFunc = func(*http.Request) (*url.URL, error) {
+	return &url.URL{Scheme: "socks5", Host: "bastion"}, nil
+}
+
 type fakeNegotiatedSerializer struct{}
 
 func (n *fakeNegotiatedSerializer) SupportedMediaTypes() []runtime.SerializerInfo {
@@ -165,6 +180,73 @@ func (n *fakeNegotiatedSerializer) DecoderToVersion(serializer runtime.Decoder,
 	return &fakeCodec{}
 }
 
+func TestRESTClientAdaptiveRateLimit(t *testing.T) {
+	c, err := RESTClientFor(&Config{
+		Host: "127.0.0.1",
+		ContentConfig: ContentConfig{
+			GroupVersion:         &api.Registry.GroupOrDie(api.GroupName).GroupVersion,
+			NegotiatedSerializer: api.Codecs,
+		},
+		QPS:               20,
+		AdaptiveRateLimit: &flowcontrol.AdaptiveRateLimiterConfig{MinQPS: 1, MaxQPS: 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limiter, ok := c.GetRateLimiter().(flowcontrol.AdaptiveRateLimiter)
+	if !ok {
+		t.Fatalf("expected an adaptive rate limiter, got %T", c.GetRateLimiter())
+	}
+	if qps := limiter.QPS(); qps != 20 {
+		t.Errorf("expected the adaptive rate limiter to start at QPS, got %v", qps)
+	}
+
+	// an empty config adapts the rate below QPS
+	c, err = RESTClientFor(&Config{
+		Host: "127.0.0.1",
+		ContentConfig: ContentConfig{
+			GroupVersion:         &api.Registry.GroupOrDie(api.GroupName).GroupVersion,
+			NegotiatedSerializer: api.Codecs,
+		},
+		QPS:               20,
+		AdaptiveRateLimit: &flowcontrol.AdaptiveRateLimiterConfig{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limiter = c.GetRateLimiter().(flowcontrol.AdaptiveRateLimiter)
+	if qps := limiter.QPS(); qps != 20 {
+		t.Errorf("expected the adaptive rate limiter to start at QPS, got %v", qps)
+	}
+	limiter.Observe(true, 0, 0)
+	if qps := limiter.QPS(); qps != 10 {
+		t.Errorf("expected the rate to halve when throttled, got %v", qps)
+	}
+	for i := 0; i < 1000; i++ {
+		limiter.Observe(false, 0, 0)
+	}
+	if qps := limiter.QPS(); qps != 20 {
+		t.Errorf("expected the rate to recover up to QPS, got %v", qps)
+	}
+
+	fixed := flowcontrol.NewFakeAlwaysRateLimiter()
+	c, err = RESTClientFor(&Config{
+		Host: "127.0.0.1",
+		ContentConfig: ContentConfig{
+			GroupVersion:         &api.Registry.GroupOrDie(api.GroupName).GroupVersion,
+			NegotiatedSerializer: api.Codecs,
+		},
+		RateLimiter:       fixed,
+		AdaptiveRateLimit: &flowcontrol.AdaptiveRateLimiterConfig{MinQPS: 1, MaxQPS: 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.GetRateLimiter() != fixed {
+		t.Errorf("expected RateLimiter to take precedence, got %T", c.GetRateLimiter())
+	}
+}
+
 func TestAnonymousConfig(t *testing.T) {
 	f := fuzz.New().NilChance(0.0).NumElements(1, 1)
 	f.Funcs(
@@ -181,6 +263,9 @@ func TestAnonymousConfig(t *testing.T) {
 		func(fn *func(http.RoundTripper) http.RoundTripper, f fuzz.Continue) {
 			*fn = fakeWrapperFunc
 		},
+		func(fn *func(*http.Request) (*url.URL, error), f fuzz.Continue) {
+			*fn = fakeProxyFunc
+		},
 		func(r *runtime.NegotiatedSerializer, f fuzz.Continue) {
 			serializer := &fakeNegotiatedSerializer{}
 			f.Fuzz(serializer)
@@ -191,6 +276,26 @@ func TestAnonymousConfig(t *testing.T) {
 			f.Fuzz(limiter)
 			*r = limiter
 		},
+		func(r *RateLimitClassifier, f fuzz.Continue) {
+			classifier := &fakeClassifier{}
+			f.Fuzz(classifier)
+			*r = classifier
+		},
+		func(r *RetryPolicy, f fuzz.Continue) {
+			policy := &ExponentialRetryPolicy{}
+			f.Fuzz(policy)
+			*r = policy
+		},
+		func(r *transport.Tracer, f fuzz.Continue) {
+			*r = transport.NewInMemoryTracer()
+		},
+		func(r **transport.RequestLoggerConfig, f fuzz.Continue) {
+			*r = &transport.RequestLoggerConfig{
+				Sink:          transport.NewJSONRequestLogSink(ioutil.Discard),
+				RedactHeaders: []string{f.RandString()},
+				MaxBodyBytes:  f.Int(),
+			}
+		},
 		// Authentication does not require fuzzer
 		func(r *AuthProviderConfigPersister, f fuzz.Continue) {},
 		func(r *clientcmdapi.AuthProviderConfig, f fuzz.Continue) {
@@ -207,10 +312,12 @@ func TestAnonymousConfig(t *testing.T) {
 		// is added to Config, update AnonymousClientConfig to preserve the field otherwise.
 		expected.Impersonate = ImpersonationConfig{}
 		expected.BearerToken = ""
+		expected.BearerTokenFile = ""
 		expected.Username = ""
 		expected.Password = ""
 		expected.AuthProvider = nil
 		expected.AuthConfigPersister = nil
+		expected.ExecProvider = nil
 		expected.TLSClientConfig.CertData = nil
 		expected.TLSClientConfig.CertFile = ""
 		expected.TLSClientConfig.KeyData = nil
@@ -224,6 +331,14 @@ func TestAnonymousConfig(t *testing.T) {
 			actual.WrapTransport = nil
 			expected.WrapTransport = nil
 		}
+		if actual.Proxy == nil {
+			t.Fatalf("AnonymousClientConfig dropped the Proxy field")
+		} else if u, _ := actual.Proxy(nil); u.String() != "socks5://bastion" {
+			t.Fatalf("AnonymousClientConfig changed the Proxy field: %v", u)
+		} else {
+			actual.Proxy = nil
+			expected.Proxy = nil
+		}
 
 		if !reflect.DeepEqual(*actual, expected) {
 			t.Fatalf("AnonymousClientConfig dropped unexpected fields, identify whether they are security related or not: %s", diff.ObjectGoPrintDiff(expected, actual))
@@ -0,0 +1,119 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/lavalamp/client-go-flat/util/flowcontrol"
+)
+
+const (
+	// endpointBackoffInitial is how long an endpoint is avoided after it failed once.
+	endpointBackoffInitial = 1 * time.Second
+	// endpointBackoffMax caps how long an endpoint that keeps failing is avoided.
+	endpointBackoffMax = 30 * time.Second
+)
+
+// endpoints tracks the health of the apiservers a client can send requests to,
+// and picks the one every attempt goes to.  An endpoint that could not be
+// reached, or that answered as overloaded or failing, is backed off and the
+// next healthy one is used instead, until its backoff expired.
+type endpoints struct {
+	// urls lists the base URLs of the endpoints, in the order they are preferred.
+	urls []*url.URL
+	// health backs off the endpoints keyed by host.
+	health *URLBackoff
+}
+
+// newEndpoints returns the endpoints for urls.  If randomize is set their
+// order is shuffled, so that clients spread over the endpoints.
+func newEndpoints(urls []*url.URL, randomize bool) *endpoints {
+	ordered := make([]*url.URL, len(urls))
+	if randomize {
+		for i, j := range rand.Perm(len(urls)) {
+			ordered[i] = urls[j]
+		}
+	} else {
+		copy(ordered, urls)
+	}
+	return &endpoints{
+		urls:   ordered,
+		health: &URLBackoff{Backoff: flowcontrol.NewBackOff(endpointBackoffInitial, endpointBackoffMax)},
+	}
+}
+
+// pick returns the first endpoint that is not backed off.  If all of them are,
+// the one with the shortest backoff is returned.
+func (e *endpoints) pick() *url.URL {
+	var best *url.URL
+	var bestBackoff time.Duration
+	for _, u := range e.urls {
+		if !e.health.inBackoff(u) {
+			return u
+		}
+		if backoff := e.health.CalculateBackoff(u); best == nil || backoff < bestBackoff {
+			best, bestBackoff = u, backoff
+		}
+	}
+	return best
+}
+
+// update records the outcome of an attempt to send a request to the endpoint
+// at u.  Requests cancelled by the caller say nothing about the endpoint.
+func (e *endpoints) update(u *url.URL, resp *http.Response, err error) {
+	if uerr, ok := err.(*url.Error); ok && (uerr.Err == context.Canceled || uerr.Err == context.DeadlineExceeded) {
+		return
+	}
+	if err != nil {
+		glog.V(4).Infof("Endpoint %s failed, backing off: %v", u.Host, err)
+		e.health.UpdateBackoff(u, err, http.StatusServiceUnavailable)
+		return
+	}
+	e.health.UpdateBackoff(u, nil, resp.StatusCode)
+}
+
+// endpointsFor returns the endpoints of config.Host, whose base URL is base,
+// and config.Endpoints, or nil if config has no further endpoints.  They must
+// all use the same scheme and path, since a request keeps its path when it
+// fails over to another endpoint.
+func endpointsFor(config *Config, base *url.URL) (*endpoints, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, nil
+	}
+	urls := []*url.URL{base}
+	for _, host := range config.Endpoints {
+		hostConfig := *config
+		hostConfig.Host = host
+		hostURL, _, err := defaultServerUrlFor(&hostConfig)
+		if err != nil {
+			return nil, err
+		}
+		normalizeBaseURL(hostURL)
+		if hostURL.Scheme != base.Scheme || hostURL.Path != base.Path {
+			return nil, fmt.Errorf("endpoint %q must use the same scheme and path as host %q", host, config.Host)
+		}
+		urls = append(urls, hostURL)
+	}
+	return newEndpoints(urls, config.RandomizeEndpoints), nil
+}
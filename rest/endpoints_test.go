@@ -0,0 +1,177 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/pkg/api"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	"github.com/lavalamp/client-go-flat/util/clock"
+	"github.com/lavalamp/client-go-flat/util/flowcontrol"
+)
+
+func TestEndpointsPick(t *testing.T) {
+	a, b, c := parse("https://a:6443/"), parse("https://b:6443/"), parse("https://c:6443/")
+	fakeClock := clock.NewFakeClock(time.Now())
+	e := newEndpoints([]*url.URL{a, b, c}, false)
+	e.health = &URLBackoff{Backoff: flowcontrol.NewFakeBackOff(time.Second, 10*time.Second, fakeClock)}
+
+	ok := &http.Response{StatusCode: http.StatusOK}
+	dialErr := &url.Error{Op: "Get", URL: a.String(), Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+
+	if u := e.pick(); u != a {
+		t.Fatalf("expected the first endpoint, got %v", u)
+	}
+	e.update(a, nil, dialErr)
+	if u := e.pick(); u != b {
+		t.Fatalf("expected to fail over to the second endpoint, got %v", u)
+	}
+	// a request cancelled by the caller says nothing about the endpoint
+	e.update(b, nil, &url.Error{Op: "Get", URL: b.String(), Err: context.Canceled})
+	if u := e.pick(); u != b {
+		t.Fatalf("expected to stay on the second endpoint, got %v", u)
+	}
+	e.update(b, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+	e.update(b, &http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+	if u := e.pick(); u != c {
+		t.Fatalf("expected to fail over to the third endpoint, got %v", u)
+	}
+	e.update(c, &http.Response{StatusCode: http.StatusTooManyRequests}, nil)
+	// with every endpoint backed off, the one recovering first is used
+	if u := e.pick(); u != a {
+		t.Fatalf("expected the endpoint with the shortest backoff, got %v", u)
+	}
+
+	fakeClock.Step(time.Second)
+	if u := e.pick(); u != a {
+		t.Fatalf("expected the first endpoint once its backoff expired, got %v", u)
+	}
+	e.update(a, ok, nil)
+	if u := e.pick(); u != a || e.health.CalculateBackoff(a) != 0 {
+		t.Fatalf("expected the first endpoint to be healthy again, got %v", u)
+	}
+}
+
+func TestEndpointsFor(t *testing.T) {
+	config := &Config{Host: "https://a:6443/prefix", Endpoints: []string{"https://b:6443/prefix", "https://c:6443/prefix"}}
+	base, _, err := defaultServerUrlFor(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	normalizeBaseURL(base)
+
+	e, err := endpointsFor(config, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var hosts []string
+	for _, u := range e.urls {
+		hosts = append(hosts, u.Host+u.Path)
+	}
+	if expected := []string{"a:6443/prefix/", "b:6443/prefix/", "c:6443/prefix/"}; !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected endpoints %v, got %v", expected, hosts)
+	}
+
+	config.RandomizeEndpoints = true
+	if e, err = endpointsFor(config, base); err != nil || len(e.urls) != 3 {
+		t.Errorf("expected 3 shuffled endpoints, got %v: %v", e, err)
+	}
+
+	if e, err := endpointsFor(&Config{Host: "https://a:6443"}, base); e != nil || err != nil {
+		t.Errorf("expected no endpoints for a single host, got %v: %v", e, err)
+	}
+	for _, endpoint := range []string{"http://b:6443/prefix", "https://b:6443/other"} {
+		config.Endpoints = []string{endpoint}
+		if _, err := endpointsFor(config, base); err == nil {
+			t.Errorf("expected an error for endpoint %s", endpoint)
+		}
+	}
+}
+
+func TestRESTClientFailover(t *testing.T) {
+	status := &metav1.Status{Status: metav1.StatusSuccess}
+	body, _ := runtime.Encode(api.Codecs.LegacyCodec(v1.SchemeGroupVersion), status)
+
+	var lock sync.Mutex
+	var servedBy []string
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			servedBy = append(servedBy, name)
+			lock.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			if req.URL.Query().Get("watch") != "true" {
+				w.Write(body)
+			}
+		}))
+	}
+	first, second := newServer("first"), newServer("second")
+	defer second.Close()
+
+	c, err := RESTClientFor(&Config{
+		Host:      first.URL,
+		Endpoints: []string{second.URL},
+		ContentConfig: ContentConfig{
+			GroupVersion:         &api.Registry.GroupOrDie(api.GroupName).GroupVersion,
+			NegotiatedSerializer: api.Codecs,
+		},
+		RetryPolicy: &ExponentialRetryPolicy{MaxAttempts: 3},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.Get().Namespace("ns").Resource("pods").Name("foo").Do().Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first.Close()
+	result := c.Get().Namespace("ns").Resource("pods").Name("foo").Do()
+	if result.Error() != nil {
+		t.Fatalf("unexpected error: %v", result.Error())
+	}
+	if result.Attempts() != 2 {
+		t.Errorf("expected the request to fail over after one attempt, got %d attempts", result.Attempts())
+	}
+
+	// watches are established on the healthy endpoint right away
+	w, err := c.Get().Namespace("ns").Resource("pods").Param("watch", "true").Watch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range w.ResultChan() {
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if expected := []string{"first", "second", "second"}; !reflect.DeepEqual(servedBy, expected) {
+		t.Errorf("expected requests to be served by %v, got %v", expected, servedBy)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+// DefaultRateLimitClass is the rate limit class of requests throttled by the
+// rate limiter of the client rather than one of their own class.
+const DefaultRateLimitClass = "default"
+
+// RateLimitClassifier assigns requests to rate limit classes, so that requests
+// of one kind, such as a storm of status updates, don't hold up requests of
+// another kind that are throttled separately.
+type RateLimitClassifier interface {
+	// Classify returns the rate limit class of a request with verb for
+	// resource and subresource, which are empty for requests that are not for
+	// a resource. Watches are classified with the verb WATCH.
+	Classify(verb, resource, subresource string) string
+}
+
+// RateLimitClassifierFunc is a function that implements RateLimitClassifier.
+type RateLimitClassifierFunc func(verb, resource, subresource string) string
+
+// Classify implements RateLimitClassifier.
+func (f RateLimitClassifierFunc) Classify(verb, resource, subresource string) string {
+	return f(verb, resource, subresource)
+}
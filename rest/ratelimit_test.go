@@ -0,0 +1,114 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/tools/metrics"
+	"github.com/lavalamp/client-go-flat/util/flowcontrol"
+)
+
+// countingLimiter counts the requests it throttles.
+type countingLimiter struct {
+	fakeLimiter
+	accepted int
+}
+
+func (l *countingLimiter) Accept() {
+	l.accepted++
+}
+
+// testRateLimiterMetric records the classes waits are observed for.
+type testRateLimiterMetric struct {
+	lock    sync.Mutex
+	classes []string
+}
+
+func (m *testRateLimiterMetric) ObserveWait(class string, wait time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.classes = append(m.classes, class)
+}
+
+func TestRequestRateLimitClasses(t *testing.T) {
+	metric := &testRateLimiterMetric{}
+	metrics.RegisterRateLimiter(metric)
+
+	defaultLimiter := &countingLimiter{}
+	statusLimiter := &countingLimiter{}
+	watchLimiter := &countingLimiter{}
+	classifier := RateLimitClassifierFunc(func(verb, resource, subresource string) string {
+		switch {
+		case verb == "WATCH":
+			return "watch"
+		case subresource == "status":
+			return "status"
+		case verb == "DELETE":
+			return "unlimited"
+		}
+		return ""
+	})
+
+	newRequest := func(verb, resource, subresource string) *Request {
+		return &Request{
+			verb:        verb,
+			resource:    resource,
+			subresource: subresource,
+			baseURL:     &url.URL{Host: "localhost"},
+			client: clientFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+			}),
+			backoffMgr:          &NoBackoff{},
+			throttle:            defaultLimiter,
+			rateLimitClassifier: classifier,
+			classRateLimiters: map[string]flowcontrol.RateLimiter{
+				"status": statusLimiter,
+				"watch":  watchLimiter,
+			},
+		}
+	}
+	newRequest("GET", "pods", "").Do()
+	newRequest("PUT", "pods", "status").Do()
+	newRequest("PUT", "pods", "status").Do()
+	newRequest("DELETE", "pods", "").Do()
+	newRequest("GET", "pods", "").tryThrottleAs("WATCH")
+
+	if defaultLimiter.accepted != 2 || statusLimiter.accepted != 2 || watchLimiter.accepted != 1 {
+		t.Errorf("unexpected throttling, default: %d, status: %d, watch: %d", defaultLimiter.accepted, statusLimiter.accepted, watchLimiter.accepted)
+	}
+	expected := []string{DefaultRateLimitClass, "status", "status", DefaultRateLimitClass, "watch"}
+	if !reflect.DeepEqual(metric.classes, expected) {
+		t.Errorf("expected waits observed for %v, got %v", expected, metric.classes)
+	}
+}
+
+func TestRequestWatchNotThrottledByDefault(t *testing.T) {
+	limiter := &countingLimiter{}
+	r := &Request{verb: "GET", baseURL: &url.URL{Host: "localhost"}, throttle: limiter}
+	r.tryThrottleAs("WATCH")
+	if limiter.accepted != 0 {
+		t.Errorf("expected watches to bypass the rate limiter of the client")
+	}
+}
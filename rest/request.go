@@ -30,6 +30,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -46,6 +47,7 @@ import (
 	"github.com/lavalamp/client-go-flat/pkg/api/v1"
 	restclientwatch "github.com/lavalamp/client-go-flat/rest/watch"
 	"github.com/lavalamp/client-go-flat/tools/metrics"
+	"github.com/lavalamp/client-go-flat/transport"
 	"github.com/lavalamp/client-go-flat/util/flowcontrol"
 )
 
@@ -114,8 +116,16 @@ type Request struct {
 	// This is only used for per-request timeouts, deadlines, and cancellations.
 	ctx context.Context
 
-	backoffMgr BackoffManager
-	throttle   flowcontrol.RateLimiter
+	backoffMgr  BackoffManager
+	throttle    flowcontrol.RateLimiter
+	retryPolicy RetryPolicy
+	tracer      transport.Tracer
+
+	watchIdleTimeout time.Duration
+	endpoints        *endpoints
+
+	rateLimitClassifier RateLimitClassifier
+	classRateLimiters   map[string]flowcontrol.RateLimiter
 }
 
 // NewRequest creates a new request helper object for accessing runtime.Objects on a server.
@@ -564,6 +574,13 @@ func (r *Request) Body(obj interface{}) *Request {
 	return r
 }
 
+// RetryPolicy sets the policy deciding whether the request is sent again after
+// an attempt failed.  DefaultRetryPolicy is used if none is set.
+func (r *Request) RetryPolicy(policy RetryPolicy) *Request {
+	r.retryPolicy = policy
+	return r
+}
+
 // Context adds a context to the request. Contexts are only used for
 // timeouts, deadlines, and cancellations.
 func (r *Request) Context(ctx context.Context) *Request {
@@ -630,48 +647,117 @@ func (r Request) finalURLTemplate() url.URL {
 }
 
 func (r *Request) tryThrottle() {
+	r.tryThrottleAs(r.verb)
+}
+
+// tryThrottleAs waits for the rate limiter of the class of the request, when
+// classified with verb.
+func (r *Request) tryThrottleAs(verb string) {
+	class, throttle := r.throttleFor(verb)
+	if throttle == nil {
+		return
+	}
 	now := time.Now()
-	if r.throttle != nil {
-		r.throttle.Accept()
+	throttle.Accept()
+	latency := time.Since(now)
+	metrics.RateLimiterWait.ObserveWait(class, latency)
+	if latency > longThrottleLatency {
+		glog.V(4).Infof("Throttling request took %v, class %s, request: %s:%s", latency, class, r.verb, r.URL().String())
 	}
-	if latency := time.Since(now); latency > longThrottleLatency {
-		glog.V(4).Infof("Throttling request took %v, request: %s:%s", latency, r.verb, r.URL().String())
+}
+
+// throttleFor returns the rate limit class of the request when classified with verb, and
+// the rate limiter of that class, if any.  Requests of classes without a rate limiter of
+// their own are throttled by the rate limiter of the client, except for watches.
+func (r *Request) throttleFor(verb string) (string, flowcontrol.RateLimiter) {
+	if r.rateLimitClassifier != nil {
+		class := r.rateLimitClassifier.Classify(verb, r.resource, r.subresource)
+		if throttle, ok := r.classRateLimiters[class]; ok {
+			return class, throttle
+		}
 	}
+	if verb == "WATCH" {
+		return "", nil
+	}
+	return DefaultRateLimitClass, r.throttle
+}
+
+// adaptThrottle reports the response to an attempt to an adaptive rate limiter, so that
+// it slows down when the server throttles requests or responds slowly.
+func (r *Request) adaptThrottle(resp *http.Response, err error, latency time.Duration) {
+	_, throttle := r.throttleFor(r.verb)
+	limiter, ok := throttle.(flowcontrol.AdaptiveRateLimiter)
+	if !ok || err != nil {
+		return
+	}
+	seconds, wait := checkWait(resp)
+	throttled := resp.StatusCode == errors.StatusTooManyRequests || wait
+	limiter.Observe(throttled, time.Duration(seconds)*time.Second, latency)
 }
 
 // Watch attempts to begin watching the requested location.
 // Returns a watch.Interface, or an error.
 func (r *Request) Watch() (watch.Interface, error) {
 	// We specifically don't want to rate limit watches, so we
-	// don't use r.throttle here.
+	// don't use r.throttle here.  Only a rate limiter of the rate
+	// limit class of watches, if there is one, throttles them.
 	if r.err != nil {
 		return nil, r.err
 	}
 	if r.serializers.Framer == nil {
 		return nil, fmt.Errorf("watching resources is not possible with this client (content-type: %s)", r.content.ContentType)
 	}
+	r.tryThrottleAs("WATCH")
 
-	url := r.URL().String()
-	req, err := http.NewRequest(r.verb, url, r.body)
-	if err != nil {
-		return nil, err
-	}
-	if r.ctx != nil {
-		req = req.WithContext(r.ctx)
-	}
-	req.Header = r.headers
 	client := r.client
 	if client == nil {
 		client = http.DefaultClient
 	}
-	r.backoffMgr.Sleep(r.backoffMgr.CalculateBackoff(r.URL()))
-	resp, err := client.Do(req)
-	updateURLMetrics(r, resp, err)
-	if r.baseURL != nil {
+
+	ctx, span := r.startSpan("WATCH")
+	var url string
+	var req *http.Request
+	var resp *http.Response
+	var err error
+	var attempts int
+	for attempts = 1; ; attempts++ {
+		r.selectEndpoint()
+		url = r.URL().String()
+		req, err = http.NewRequest(r.verb, url, r.body)
 		if err != nil {
-			r.backoffMgr.UpdateBackoff(r.baseURL, err, 0)
+			if span != nil {
+				recordSpan(span, attempts, nil, err)
+				span.End()
+			}
+			return nil, err
+		}
+		if ctx != nil {
+			req = req.WithContext(ctx)
+		}
+		req.Header = r.headers
+		r.backoffMgr.Sleep(r.backoffMgr.CalculateBackoff(r.URL()))
+		sent := time.Now()
+		resp, err = client.Do(req)
+		updateURLMetrics(r, resp, err)
+		r.updateEndpoint(resp, err)
+		r.adaptThrottle(resp, err, time.Since(sent))
+		if r.baseURL != nil {
+			if err != nil {
+				r.backoffMgr.UpdateBackoff(r.baseURL, err, 0)
+			} else {
+				r.backoffMgr.UpdateBackoff(r.baseURL, err, resp.StatusCode)
+			}
+		}
+		if !r.retry(attempts, req, resp, err) {
+			break
+		}
+	}
+	if span != nil {
+		recordSpan(span, attempts, resp, err)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			span.End()
 		} else {
-			r.backoffMgr.UpdateBackoff(r.baseURL, err, resp.StatusCode)
+			resp.Body = &spanClosingBody{resp.Body, span}
 		}
 	}
 	if err != nil {
@@ -689,11 +775,51 @@ func (r *Request) Watch() (watch.Interface, error) {
 		}
 		return nil, fmt.Errorf("for request '%+v', got status: %v", url, resp.StatusCode)
 	}
+	if r.watchIdleTimeout > 0 {
+		resp.Body = newIdleTimeoutBody(resp.Body, r.watchIdleTimeout)
+	}
 	framer := r.serializers.Framer.NewFrameReader(resp.Body)
 	decoder := streaming.NewDecoder(framer, r.serializers.StreamingSerializer)
 	return watch.NewStreamWatcher(restclientwatch.NewDecoder(decoder, r.serializers.Decoder)), nil
 }
 
+// idleTimeoutBody closes the response body it wraps when no data was read from
+// it for timeout, so that a watch on a connection that went away without being
+// closed ends with an error instead of blocking forever.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	idle    int32
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, b.expire)
+	return b
+}
+
+func (b *idleTimeoutBody) expire() {
+	atomic.StoreInt32(&b.idle, 1)
+	b.ReadCloser.Close()
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if atomic.LoadInt32(&b.idle) == 1 {
+		return n, fmt.Errorf("no data received from the server for %v, closing the stream", b.timeout)
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
+
 // updateURLMetrics is a convenience function for pushing metrics.
 // It also handles corner cases for incomplete/invalid request data.
 func updateURLMetrics(req *Request, resp *http.Response, err error) {
@@ -723,27 +849,58 @@ func (r *Request) Stream() (io.ReadCloser, error) {
 
 	r.tryThrottle()
 
-	url := r.URL().String()
-	req, err := http.NewRequest(r.verb, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	if r.ctx != nil {
-		req = req.WithContext(r.ctx)
-	}
-	req.Header = r.headers
 	client := r.client
 	if client == nil {
 		client = http.DefaultClient
 	}
-	r.backoffMgr.Sleep(r.backoffMgr.CalculateBackoff(r.URL()))
-	resp, err := client.Do(req)
-	updateURLMetrics(r, resp, err)
-	if r.baseURL != nil {
+
+	ctx, span := r.startSpan("STREAM")
+	var url string
+	var req *http.Request
+	var resp *http.Response
+	var err error
+	var attempts int
+	for attempts = 1; ; attempts++ {
+		r.selectEndpoint()
+		url = r.URL().String()
+		req, err = http.NewRequest(r.verb, url, nil)
 		if err != nil {
-			r.backoffMgr.UpdateBackoff(r.URL(), err, 0)
+			if span != nil {
+				recordSpan(span, attempts, nil, err)
+				span.End()
+			}
+			return nil, err
+		}
+		if ctx != nil {
+			req = req.WithContext(ctx)
+		}
+		req.Header = r.headers
+		r.backoffMgr.Sleep(r.backoffMgr.CalculateBackoff(r.URL()))
+		if attempts > 1 {
+			r.tryThrottle()
+		}
+		sent := time.Now()
+		resp, err = client.Do(req)
+		updateURLMetrics(r, resp, err)
+		r.updateEndpoint(resp, err)
+		r.adaptThrottle(resp, err, time.Since(sent))
+		if r.baseURL != nil {
+			if err != nil {
+				r.backoffMgr.UpdateBackoff(r.URL(), err, 0)
+			} else {
+				r.backoffMgr.UpdateBackoff(r.URL(), err, resp.StatusCode)
+			}
+		}
+		if !r.retry(attempts, req, resp, err) {
+			break
+		}
+	}
+	if span != nil {
+		recordSpan(span, attempts, resp, err)
+		if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			span.End()
 		} else {
-			r.backoffMgr.UpdateBackoff(r.URL(), err, resp.StatusCode)
+			resp.Body = &spanClosingBody{resp.Body, span}
 		}
 	}
 	if err != nil {
@@ -770,8 +927,9 @@ func (r *Request) Stream() (io.ReadCloser, error) {
 // request connects to the server and invokes the provided function when a server response is
 // received. It handles retry behavior and up front validation of requests. It will invoke
 // fn at most once. It will return an error if a problem occurred prior to connecting to the
-// server - the provided function is responsible for handling server errors.
-func (r *Request) request(fn func(*http.Request, *http.Response)) error {
+// server - the provided function is responsible for handling server errors. It returns the
+// number of attempts made to send the request.
+func (r *Request) request(fn func(*http.Request, *http.Response)) (int, error) {
 	//Metrics for total request latency
 	start := time.Now()
 	defer func() {
@@ -780,15 +938,15 @@ func (r *Request) request(fn func(*http.Request, *http.Response)) error {
 
 	if r.err != nil {
 		glog.V(4).Infof("Error in request: %v", r.err)
-		return r.err
+		return 0, r.err
 	}
 
 	// TODO: added to catch programmer errors (invoking operations with an object with an empty namespace)
 	if (r.verb == "GET" || r.verb == "PUT" || r.verb == "DELETE") && r.namespaceSet && len(r.resourceName) > 0 && len(r.namespace) == 0 {
-		return fmt.Errorf("an empty namespace may not be set when a resource name is provided")
+		return 0, fmt.Errorf("an empty namespace may not be set when a resource name is provided")
 	}
 	if (r.verb == "POST") && r.namespaceSet && len(r.namespace) == 0 {
-		return fmt.Errorf("an empty namespace may not be set during creation")
+		return 0, fmt.Errorf("an empty namespace may not be set during creation")
 	}
 
 	client := r.client
@@ -796,86 +954,153 @@ func (r *Request) request(fn func(*http.Request, *http.Response)) error {
 		client = http.DefaultClient
 	}
 
-	// Right now we make about ten retry attempts if we get a Retry-After response.
-	// TODO: Change to a timeout based approach.
-	maxRetries := 10
-	retries := 0
-	for {
+	ctx, span := r.startSpan(r.verb)
+	for attempts := 1; ; attempts++ {
+		r.selectEndpoint()
 		url := r.URL().String()
 		req, err := http.NewRequest(r.verb, url, r.body)
 		if err != nil {
-			return err
+			if span != nil {
+				recordSpan(span, attempts, nil, err)
+				span.End()
+			}
+			return attempts - 1, err
 		}
-		if r.ctx != nil {
-			req = req.WithContext(r.ctx)
+		if ctx != nil {
+			req = req.WithContext(ctx)
 		}
 		req.Header = r.headers
 
 		r.backoffMgr.Sleep(r.backoffMgr.CalculateBackoff(r.URL()))
-		if retries > 0 {
+		if attempts > 1 {
 			// We are retrying the request that we already send to apiserver
 			// at least once before.
 			// This request should also be throttled with the client-internal throttler.
 			r.tryThrottle()
 		}
+		sent := time.Now()
 		resp, err := client.Do(req)
 		updateURLMetrics(r, resp, err)
+		r.updateEndpoint(resp, err)
+		r.adaptThrottle(resp, err, time.Since(sent))
 		if err != nil {
 			r.backoffMgr.UpdateBackoff(r.URL(), err, 0)
 		} else {
 			r.backoffMgr.UpdateBackoff(r.URL(), err, resp.StatusCode)
 		}
+		if r.retry(attempts, req, resp, err) {
+			continue
+		}
+		if span != nil {
+			recordSpan(span, attempts, resp, err)
+			defer span.End()
+		}
 		if err != nil {
-			// "Connection reset by peer" is usually a transient error.
-			// Thus in case of "GET" operations, we simply retry it.
-			// We are not automatically retrying "write" operations, as
-			// they are not idempotent.
-			if !net.IsConnectionReset(err) || r.verb != "GET" {
-				return err
-			}
-			// For the purpose of retry, we set the artificial "retry-after" response.
-			// TODO: Should we clean the original response if it exists?
-			resp = &http.Response{
-				StatusCode: http.StatusInternalServerError,
-				Header:     http.Header{"Retry-After": []string{"1"}},
-				Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
-			}
+			return attempts, err
 		}
 
-		done := func() bool {
-			// Ensure the response body is fully read and closed
-			// before we reconnect, so that we reuse the same TCP
-			// connection.
-			defer func() {
-				const maxBodySlurpSize = 2 << 10
-				if resp.ContentLength <= maxBodySlurpSize {
-					io.Copy(ioutil.Discard, &io.LimitedReader{R: resp.Body, N: maxBodySlurpSize})
-				}
-				resp.Body.Close()
-			}()
-
-			retries++
-			if seconds, wait := checkWait(resp); wait && retries < maxRetries {
-				if seeker, ok := r.body.(io.Seeker); ok && r.body != nil {
-					_, err := seeker.Seek(0, 0)
-					if err != nil {
-						glog.V(4).Infof("Could not retry request, can't Seek() back to beginning of body for %T", r.body)
-						fn(req, resp)
-						return true
-					}
-				}
-
-				glog.V(4).Infof("Got a Retry-After %s response for attempt %d to %v", seconds, retries, url)
-				r.backoffMgr.Sleep(time.Duration(seconds) * time.Second)
-				return false
-			}
+		func() {
+			defer drainAndClose(resp)
 			fn(req, resp)
-			return true
 		}()
-		if done {
-			return nil
+		return attempts, nil
+	}
+}
+
+// selectEndpoint points the request at the endpoint its next attempt goes to,
+// if the client fails over between several apiservers.
+func (r *Request) selectEndpoint() {
+	if r.endpoints != nil {
+		r.baseURL = r.endpoints.pick()
+	}
+}
+
+// updateEndpoint records the outcome of an attempt for the health of the
+// endpoint it went to.
+func (r *Request) updateEndpoint(resp *http.Response, err error) {
+	if r.endpoints != nil {
+		r.endpoints.update(r.baseURL, resp, err)
+	}
+}
+
+// retry asks the retry policy whether req should be sent again after its
+// attempts-th attempt ended with resp or err.  If so, it rewinds the request
+// body, releases resp, records the retry, waits as long as the policy asked
+// and returns true.
+func (r *Request) retry(attempts int, req *http.Request, resp *http.Response, err error) bool {
+	policy := r.retryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy
+	}
+	delay, retry := policy.ShouldRetry(attempts, req, resp, err)
+	if !retry {
+		return false
+	}
+
+	if seeker, ok := r.body.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, 0); err != nil {
+			glog.V(4).Infof("Could not retry request, can't Seek() back to beginning of body for %T", r.body)
+			return false
 		}
+	} else if r.body != nil {
+		glog.V(4).Infof("Could not retry request, can't rewind a body of type %T", r.body)
+		return false
+	}
+
+	// The wait ends early when the context of the request is done, and the
+	// response or error of the last attempt is returned.
+	if !r.waitToRetry(delay) {
+		glog.V(4).Infof("Not retrying attempt %d to %v, the request context is done", attempts, req.URL)
+		return false
+	}
+
+	host := "none"
+	if r.baseURL != nil {
+		host = r.baseURL.Host
+	}
+	if err != nil {
+		metrics.RequestRetry.IncrementRetry("<error>", r.verb, host)
+		glog.V(4).Infof("Got an error for attempt %d to %v, retrying after %v: %v", attempts, req.URL, delay, err)
+	} else {
+		drainAndClose(resp)
+		metrics.RequestRetry.IncrementRetry(strconv.Itoa(resp.StatusCode), r.verb, host)
+		glog.V(4).Infof("Got a %d response for attempt %d to %v, retrying after %v", resp.StatusCode, attempts, req.URL, delay)
 	}
+	return true
+}
+
+// waitToRetry waits for delay, and returns false without waiting it out if the
+// context of the request is done, or has a deadline that comes before the wait
+// is over.
+func (r *Request) waitToRetry(delay time.Duration) bool {
+	if r.ctx == nil {
+		r.backoffMgr.Sleep(delay)
+		return true
+	}
+	if r.ctx.Err() != nil {
+		return false
+	}
+	if deadline, ok := r.ctx.Deadline(); ok && deadline.Before(time.Now().Add(delay)) {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-r.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// drainAndClose ensures the response body is fully read and closed, so that
+// the same TCP connection can be reused.
+func drainAndClose(resp *http.Response) {
+	const maxBodySlurpSize = 2 << 10
+	if resp.ContentLength <= maxBodySlurpSize {
+		io.Copy(ioutil.Discard, &io.LimitedReader{R: resp.Body, N: maxBodySlurpSize})
+	}
+	resp.Body.Close()
 }
 
 // Do formats and executes the request. Returns a Result object for easy response
@@ -890,12 +1115,13 @@ func (r *Request) Do() Result {
 	r.tryThrottle()
 
 	var result Result
-	err := r.request(func(req *http.Request, resp *http.Response) {
+	attempts, err := r.request(func(req *http.Request, resp *http.Response) {
 		result = r.transformResponse(resp, req)
 	})
 	if err != nil {
-		return Result{err: err}
+		return Result{err: err, attempts: attempts}
 	}
+	result.attempts = attempts
 	return result
 }
 
@@ -904,7 +1130,7 @@ func (r *Request) DoRaw() ([]byte, error) {
 	r.tryThrottle()
 
 	var result Result
-	err := r.request(func(req *http.Request, resp *http.Response) {
+	_, err := r.request(func(req *http.Request, resp *http.Response) {
 		result.body, result.err = ioutil.ReadAll(resp.Body)
 		glogBody("Response Body", result.body)
 		if resp.StatusCode < http.StatusOK || resp.StatusCode > http.StatusPartialContent {
@@ -1092,6 +1318,7 @@ type Result struct {
 	contentType string
 	err         error
 	statusCode  int
+	attempts    int
 
 	decoder runtime.Decoder
 }
@@ -1135,6 +1362,12 @@ func (r Result) StatusCode(statusCode *int) Result {
 	return r
 }
 
+// Attempts returns the number of times the request was sent, including
+// retries.
+func (r Result) Attempts() int {
+	return r.attempts
+}
+
 // Into stores the result into obj, if possible. If obj is nil it is ignored.
 // If the returned object is of type Status and has .Status != StatusSuccess, the
 // additional information in Status will be used to enrich the error.
@@ -44,6 +44,7 @@ import (
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/diff"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/httpstream"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/intstr"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/watch"
 	"github.com/lavalamp/client-go-flat/pkg/api"
 	"github.com/lavalamp/client-go-flat/pkg/api/v1"
@@ -843,7 +844,8 @@ func TestRequestWatch(t *testing.T) {
 	}
 	for i, testCase := range testCases {
 		t.Logf("testcase %v", testCase.Request)
-		testCase.Request.backoffMgr = &NoBackoff{}
+		// transient errors are retried, so don't actually wait between attempts
+		testCase.Request.backoffMgr = &testBackoffManager{}
 		watch, err := testCase.Request.Watch()
 		hasErr := err != nil
 		if hasErr != testCase.Err {
@@ -1219,6 +1221,57 @@ func TestCheckRetryHandles429And5xx(t *testing.T) {
 	}
 }
 
+// testAdaptiveRateLimiter records the feedback it is given.
+type testAdaptiveRateLimiter struct {
+	flowcontrol.RateLimiter
+	observed []string
+}
+
+func (l *testAdaptiveRateLimiter) Observe(throttled bool, retryAfter, latency time.Duration) {
+	l.observed = append(l.observed, fmt.Sprintf("%v %v", throttled, retryAfter))
+}
+
+func TestRequestAdaptiveThrottle(t *testing.T) {
+	limiter := &testAdaptiveRateLimiter{RateLimiter: flowcontrol.NewFakeAlwaysRateLimiter()}
+	responses := []*http.Response{
+		{StatusCode: apierrors.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"2"}}},
+		{StatusCode: http.StatusOK, Header: http.Header{}},
+	}
+	count := 0
+	req := &Request{
+		verb:    "GET",
+		baseURL: &url.URL{Host: "localhost"},
+		client: clientFunc(func(req *http.Request) (*http.Response, error) {
+			resp := responses[count]
+			count++
+			resp.Body = ioutil.NopCloser(bytes.NewReader([]byte{}))
+			return resp, nil
+		}),
+		backoffMgr: &testBackoffManager{},
+		throttle:   limiter,
+	}
+	req.Do()
+
+	expected := []string{"true 2s", "false 0s"}
+	if !reflect.DeepEqual(limiter.observed, expected) {
+		t.Errorf("expected the rate limiter to observe %v, got %v", expected, limiter.observed)
+	}
+
+	// watches report their responses too
+	limiter.observed = nil
+	count = 0
+	req.content = defaultContentConfig()
+	req.serializers = defaultSerializers()
+	watching, err := req.Watch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	watching.Stop()
+	if !reflect.DeepEqual(limiter.observed, expected) {
+		t.Errorf("expected the rate limiter to observe %v for a watch, got %v", expected, limiter.observed)
+	}
+}
+
 func BenchmarkCheckRetryClosesBody(b *testing.B) {
 	count := 0
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -1665,6 +1718,69 @@ func TestStream(t *testing.T) {
 	}
 }
 
+func TestWatchIdleTimeout(t *testing.T) {
+	done := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.Header().Set("Transfer-Encoding", "chunked")
+		w.WriteHeader(http.StatusOK)
+
+		encoder := restclientwatch.NewEncoder(streaming.NewEncoder(w, api.Codecs.LegacyCodec(v1.SchemeGroupVersion)), api.Codecs.LegacyCodec(v1.SchemeGroupVersion))
+		if err := encoder.Encode(&watch.Event{Type: watch.Added, Object: &api.Pod{ObjectMeta: metav1.ObjectMeta{Name: "first"}}}); err != nil {
+			panic(err)
+		}
+		flusher.Flush()
+		// then go silent, like a connection dropped by a load balancer
+		<-done
+	}))
+	defer testServer.Close()
+	defer close(done)
+
+	s := testRESTClient(t, testServer)
+	s.WatchIdleTimeout = 100 * time.Millisecond
+	watching, err := s.Get().Prefix("path/to/watch/thing").Watch()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, ok := <-watching.ResultChan(); !ok || got.Type != watch.Added {
+		t.Fatalf("Expected the first event, got %v", got)
+	}
+	select {
+	case _, ok := <-watching.ResultChan():
+		if ok {
+			t.Fatalf("Unexpected event")
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Expected the idle watch to be closed")
+	}
+}
+
+func TestIdleTimeoutBody(t *testing.T) {
+	r, w := io.Pipe()
+	body := newIdleTimeoutBody(r, 50*time.Millisecond)
+	go func() {
+		// keep the stream busy for longer than the timeout
+		for i := 0; i < 10; i++ {
+			time.Sleep(10 * time.Millisecond)
+			w.Write([]byte{'a'})
+		}
+	}()
+
+	buf := make([]byte, 1)
+	for i := 0; i < 10; i++ {
+		if _, err := body.Read(buf); err != nil {
+			t.Fatalf("Unexpected error on read %d: %v", i, err)
+		}
+	}
+	_, err := body.Read(buf)
+	if err == nil || !strings.Contains(err.Error(), "no data received from the server for 50ms") {
+		t.Errorf("Expected an idle timeout error, got %v", err)
+	}
+	if _, err := w.Write([]byte{'a'}); err != io.ErrClosedPipe {
+		t.Errorf("Expected the underlying body to be closed, got %v", err)
+	}
+}
+
 func testRESTClient(t testing.TB, srv *httptest.Server) *RESTClient {
 	baseURL, _ := url.Parse("http://localhost")
 	if srv != nil {
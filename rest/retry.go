@@ -0,0 +1,179 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	utilnet "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/net"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+)
+
+// RetryPolicy decides whether a request is sent again after an attempt failed.
+type RetryPolicy interface {
+	// ShouldRetry is called after the attempt-th attempt (counting from 1) to
+	// send req ended with either resp or err.  It returns whether the request
+	// should be sent again and how long to wait before doing so.  It must not
+	// read or close the body of resp.
+	ShouldRetry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// DefaultRetryPolicy is used by requests that have no RetryPolicy.  As requests
+// always have, it retries the 429 and 5xx responses that have a Retry-After
+// header and GETs whose connection was reset, for up to ten attempts.  Other
+// connection errors, such as those of an unreachable server, fail the request
+// right away and are left to the caller, such as the backoff of a reflector,
+// unless a policy such as TransientErrorRetryPolicy is set.
+var DefaultRetryPolicy RetryPolicy = &RetryAfterPolicy{MaxAttempts: 10}
+
+// TransientErrorRetryPolicy honors Retry-After responses and also retries
+// transient connection errors of idempotent requests, for up to ten attempts.
+// A request to an unreachable server fails after about 14 seconds.
+var TransientErrorRetryPolicy RetryPolicy = &ExponentialRetryPolicy{
+	MaxAttempts:  10,
+	BaseDelay:    250 * time.Millisecond,
+	MaxDelay:     2 * time.Second,
+	JitterFactor: 0.2,
+}
+
+// NoRetryPolicy never retries a request.
+var NoRetryPolicy RetryPolicy = &ExponentialRetryPolicy{MaxAttempts: 1}
+
+// RetryAfterPolicy retries requests the server asked to be retried with a
+// Retry-After header, waiting as long as the server asked.  A GET whose
+// connection was reset is retried after a second, as if the server had asked
+// for it.  Other connection errors are not retried.
+type RetryAfterPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including
+	// the first attempt.
+	MaxAttempts int
+}
+
+// ShouldRetry implements RetryPolicy.
+func (p *RetryAfterPolicy) ShouldRetry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if err != nil {
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		if req.Method != "GET" || !utilnet.IsConnectionReset(err) {
+			return 0, false
+		}
+		return time.Second, true
+	}
+	seconds, wait := checkWait(resp)
+	return time.Duration(seconds) * time.Second, wait
+}
+
+// ExponentialRetryPolicy retries requests the server asked to be retried with a
+// Retry-After header, waiting as long as the server asked.  It also retries
+// requests that failed with a transient connection error, such as a reset
+// connection, an unexpected EOF or a TLS handshake timeout, waiting
+// exponentially longer between attempts.  Such errors are only retried for
+// idempotent verbs, unless the connection could not be established at all or
+// RetryNonIdempotent is set.
+type ExponentialRetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including
+	// the first attempt.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry after a connection error.
+	// It doubles with every further retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between retries after connection errors.
+	MaxDelay time.Duration
+	// JitterFactor adds a random wait of up to JitterFactor times the delay
+	// after connection errors, to spread out clients that failed together.
+	JitterFactor float64
+	// RetryNonIdempotent allows retrying connection errors of requests that
+	// are not safe to send twice, such as a POST that may have reached the
+	// server before the connection was lost.
+	RetryNonIdempotent bool
+}
+
+// ShouldRetry implements RetryPolicy.
+func (p *ExponentialRetryPolicy) ShouldRetry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if err == nil {
+		seconds, wait := checkWait(resp)
+		return time.Duration(seconds) * time.Second, wait
+	}
+
+	if !isTransientError(err) {
+		return 0, false
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(req.Method) && !isDialError(err) {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.JitterFactor > 0 {
+		delay = wait.Jitter(delay, p.JitterFactor)
+	}
+	return delay, true
+}
+
+// isIdempotent returns true for the verbs that only read, and so can be sent
+// any number of times.  Lists and watches are GETs.
+func isIdempotent(verb string) bool {
+	switch verb {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// isTransientError returns true for connection errors that are likely to
+// succeed when the request is sent again.
+func isTransientError(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	switch {
+	case err == nil, err == context.Canceled, err == context.DeadlineExceeded:
+		return false
+	case isDialError(err), utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err):
+		return true
+	case strings.Contains(err.Error(), "TLS handshake timeout"):
+		return true
+	}
+	return false
+}
+
+// isDialError returns true if the connection to the server could not be
+// established, in which case the request was never sent.
+func isDialError(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
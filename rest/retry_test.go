@@ -0,0 +1,252 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/tools/metrics"
+)
+
+func TestExponentialRetryPolicy(t *testing.T) {
+	policy := &ExponentialRetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 3 * time.Second}
+	reset := &url.Error{Op: "Post", URL: "https://localhost", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}
+	refused := &url.Error{Op: "Post", URL: "https://localhost", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}
+	retryAfter := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}}
+
+	testCases := []struct {
+		name    string
+		verb    string
+		attempt int
+		resp    *http.Response
+		err     error
+		policy  *ExponentialRetryPolicy
+
+		retry bool
+		delay time.Duration
+	}{
+		{name: "success", verb: "GET", attempt: 1, resp: &http.Response{StatusCode: http.StatusOK}},
+		{name: "error response", verb: "GET", attempt: 1, resp: &http.Response{StatusCode: http.StatusInternalServerError}},
+		{name: "retry after", verb: "POST", attempt: 1, resp: retryAfter, retry: true, delay: 7 * time.Second},
+		{name: "retry after, out of attempts", verb: "POST", attempt: 5, resp: retryAfter},
+		{name: "eof on get", verb: "GET", attempt: 1, err: io.EOF, retry: true, delay: time.Second},
+		{name: "reset on get backs off", verb: "GET", attempt: 2, err: reset, retry: true, delay: 2 * time.Second},
+		{name: "reset on get caps delay", verb: "GET", attempt: 4, err: reset, retry: true, delay: 3 * time.Second},
+		{name: "tls handshake timeout", verb: "GET", attempt: 1, err: errors.New("net/http: TLS handshake timeout"), retry: true, delay: time.Second},
+		{name: "reset on post", verb: "POST", attempt: 1, err: reset},
+		{name: "refused on post", verb: "POST", attempt: 1, err: refused, retry: true, delay: time.Second},
+		{name: "reset on post opted in", verb: "POST", attempt: 1, err: reset, retry: true, delay: time.Second,
+			policy: &ExponentialRetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, RetryNonIdempotent: true}},
+		{name: "unknown error", verb: "GET", attempt: 1, err: errors.New("x509: certificate signed by unknown authority")},
+		{name: "cancelled", verb: "GET", attempt: 1, err: &url.Error{Op: "Get", URL: "https://localhost", Err: context.Canceled}},
+	}
+	for _, testCase := range testCases {
+		p := policy
+		if testCase.policy != nil {
+			p = testCase.policy
+		}
+		req, _ := http.NewRequest(testCase.verb, "https://localhost", nil)
+		delay, retry := p.ShouldRetry(testCase.attempt, req, testCase.resp, testCase.err)
+		if retry != testCase.retry || delay != testCase.delay {
+			t.Errorf("%s: expected retry %t after %v, got %t after %v", testCase.name, testCase.retry, testCase.delay, retry, delay)
+		}
+	}
+}
+
+func TestRetryAfterPolicy(t *testing.T) {
+	policy := &RetryAfterPolicy{MaxAttempts: 5}
+	reset := &url.Error{Op: "Get", URL: "https://localhost", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}
+	refused := &url.Error{Op: "Get", URL: "https://localhost", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}
+
+	testCases := []struct {
+		name    string
+		verb    string
+		attempt int
+		resp    *http.Response
+		err     error
+
+		retry bool
+		delay time.Duration
+	}{
+		{name: "success", attempt: 1, resp: &http.Response{StatusCode: http.StatusOK}},
+		{name: "error response", attempt: 1, resp: &http.Response{StatusCode: http.StatusInternalServerError}},
+		{name: "too many requests", attempt: 1, retry: true, delay: 7 * time.Second,
+			resp: &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}}},
+		{name: "unavailable", attempt: 1, retry: true, delay: time.Second,
+			resp: &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": []string{"1"}}}},
+		{name: "out of attempts", attempt: 5,
+			resp: &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}}},
+		{name: "conflict with retry after", attempt: 1,
+			resp: &http.Response{StatusCode: http.StatusConflict, Header: http.Header{"Retry-After": []string{"7"}}}},
+		{name: "refused", verb: "GET", attempt: 1, err: refused},
+		{name: "eof", verb: "GET", attempt: 1, err: io.EOF},
+		{name: "reset on get", verb: "GET", attempt: 1, err: reset, retry: true, delay: time.Second},
+		{name: "reset on get, out of attempts", verb: "GET", attempt: 5, err: reset},
+		{name: "reset on post", verb: "POST", attempt: 1, err: reset},
+	}
+	for _, testCase := range testCases {
+		verb := testCase.verb
+		if verb == "" {
+			verb = "GET"
+		}
+		req, _ := http.NewRequest(verb, "https://localhost", nil)
+		delay, retry := policy.ShouldRetry(testCase.attempt, req, testCase.resp, testCase.err)
+		if retry != testCase.retry || delay != testCase.delay {
+			t.Errorf("%s: expected retry %t after %v, got %t after %v", testCase.name, testCase.retry, testCase.delay, retry, delay)
+		}
+	}
+}
+
+type testRetryMetric struct {
+	lock    sync.Mutex
+	retries []string
+}
+
+func (m *testRetryMetric) IncrementRetry(code, method, host string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.retries = append(m.retries, code+" "+method+" "+host)
+}
+
+func TestRequestRetryPolicy(t *testing.T) {
+	retryMetric := &testRetryMetric{}
+	metrics.RegisterRetry(retryMetric)
+
+	newRequest := func(verb string, failures int) (*Request, *int) {
+		count := 0
+		return &Request{
+			verb:    verb,
+			baseURL: &url.URL{Host: "localhost"},
+			body:    bytes.NewReader([]byte("body")),
+			client: clientFunc(func(req *http.Request) (*http.Response, error) {
+				count++
+				if data, _ := ioutil.ReadAll(req.Body); string(data) != "body" {
+					t.Errorf("attempt %d sent an incomplete body %q", count, data)
+				}
+				if count <= failures {
+					return nil, &url.Error{Op: verb, URL: "https://localhost", Err: io.EOF}
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+				}, nil
+			}),
+			backoffMgr: &testBackoffManager{},
+		}, &count
+	}
+
+	// connection errors are not retried by default
+	req, count := newRequest("GET", 1)
+	if result := req.Do(); result.Error() == nil || *count != 1 || result.Attempts() != 1 {
+		t.Errorf("expected a single failed attempt, got %d attempts: %v", *count, result.Error())
+	}
+
+	// idempotent requests are retried when the policy opts in
+	req, count = newRequest("GET", 2)
+	req.RetryPolicy(TransientErrorRetryPolicy)
+	result := req.Do()
+	if result.Error() != nil {
+		t.Fatalf("unexpected error: %v", result.Error())
+	}
+	if *count != 3 || result.Attempts() != 3 {
+		t.Errorf("expected 3 attempts, sent %d and recorded %d", *count, result.Attempts())
+	}
+	expectedRetries := []string{"<error> GET localhost", "<error> GET localhost"}
+	if !reflect.DeepEqual(retryMetric.retries, expectedRetries) {
+		t.Errorf("expected retries %v to be recorded, got %v", expectedRetries, retryMetric.retries)
+	}
+
+	// other requests are not, unless the policy opts in
+	req, count = newRequest("POST", 1)
+	req.RetryPolicy(TransientErrorRetryPolicy)
+	if result := req.Do(); result.Error() == nil || *count != 1 || result.Attempts() != 1 {
+		t.Errorf("expected a single failed attempt, got %d attempts: %v", *count, result.Error())
+	}
+	req, count = newRequest("POST", 1)
+	req.RetryPolicy(&ExponentialRetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true})
+	if result := req.Do(); result.Error() != nil || *count != 2 {
+		t.Errorf("expected the opted in request to succeed on the second attempt, got %d attempts: %v", *count, result.Error())
+	}
+
+	// the policy bounds the number of attempts
+	req, count = newRequest("GET", 5)
+	req.RetryPolicy(&ExponentialRetryPolicy{MaxAttempts: 3})
+	if result := req.Do(); result.Error() == nil || *count != 3 || result.Attempts() != 3 {
+		t.Errorf("expected 3 failed attempts, got %d: %v", *count, result.Error())
+	}
+}
+
+func TestRequestRetryContext(t *testing.T) {
+	newRequest := func(ctx context.Context) (*Request, *int) {
+		count := 0
+		return &Request{
+			verb:        "GET",
+			baseURL:     &url.URL{Host: "localhost"},
+			content:     defaultContentConfig(),
+			serializers: defaultSerializers(),
+			ctx:         ctx,
+			client: clientFunc(func(req *http.Request) (*http.Response, error) {
+				count++
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Header:     http.Header{"Retry-After": []string{"30"}},
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte{})),
+				}, nil
+			}),
+			backoffMgr: &testBackoffManager{},
+		}, &count
+	}
+
+	// a cancelled request stops waiting for the server
+	ctx, cancel := context.WithCancel(context.Background())
+	req, count := newRequest(ctx)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	if result := req.Do(); result.Error() == nil || *count != 1 {
+		t.Errorf("expected a single failed attempt, got %d: %v", *count, result.Error())
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the wait to end with the context, took %v", elapsed)
+	}
+
+	// a request that would expire during the wait doesn't wait at all
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, count = newRequest(ctx)
+	start = time.Now()
+	if result := req.Do(); result.Error() == nil || *count != 1 {
+		t.Errorf("expected a single failed attempt, got %d: %v", *count, result.Error())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected no wait past the deadline, took %v", elapsed)
+	}
+}
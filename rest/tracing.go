@@ -0,0 +1,87 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/lavalamp/client-go-flat/transport"
+)
+
+// startSpan starts a span for the request if it has a tracer, naming it after
+// the operation and the resource. It returns the context the HTTP requests
+// should be sent with, which carries the span, and a nil span if the request
+// is not traced.
+func (r *Request) startSpan(operation string) (context.Context, transport.Span) {
+	ctx := r.ctx
+	if r.tracer == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	name := operation
+	if len(r.resource) > 0 {
+		name += " " + strings.ToLower(r.resource)
+		if len(r.subresource) > 0 {
+			name += "/" + r.subresource
+		}
+	}
+	ctx, span := r.tracer.StartSpan(ctx, name)
+	span.SetAttribute("verb", r.verb)
+	for key, value := range map[string]string{
+		"resource":    r.resource,
+		"subresource": r.subresource,
+		"namespace":   r.namespace,
+		"name":        r.resourceName,
+	} {
+		if len(value) > 0 {
+			span.SetAttribute(key, value)
+		}
+	}
+	return ctx, span
+}
+
+// recordSpan records the outcome of the last of attempts on span.
+func recordSpan(span transport.Span, attempts int, resp *http.Response, err error) {
+	if attempts > 1 {
+		span.SetAttribute("retries", attempts-1)
+	}
+	if resp != nil {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+	}
+	if err != nil {
+		span.SetAttribute("error", err.Error())
+	}
+}
+
+// spanClosingBody ends a span when the response body it wraps is closed, so
+// that the span of a watch or a stream covers the whole time it is read.
+type spanClosingBody struct {
+	io.ReadCloser
+	span transport.Span
+}
+
+func (b *spanClosingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.span.End()
+	return err
+}
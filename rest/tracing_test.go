@@ -0,0 +1,135 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/pkg/api"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	"github.com/lavalamp/client-go-flat/transport"
+)
+
+// retryServerErrorsOnce retries a 500 response once, without waiting.
+type retryServerErrorsOnce struct{}
+
+func (retryServerErrorsOnce) ShouldRetry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	return 0, attempt == 1 && resp != nil && resp.StatusCode == http.StatusInternalServerError
+}
+
+func TestRequestTracing(t *testing.T) {
+	status := &metav1.Status{Status: metav1.StatusSuccess}
+	body, _ := runtime.Encode(api.Codecs.LegacyCodec(v1.SchemeGroupVersion), status)
+
+	var lock sync.Mutex
+	var traceParents []string
+	endWatch := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		traceParents = append(traceParents, req.Header.Get(transport.TraceParentHeader))
+		first := len(traceParents) == 1
+		lock.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.URL.Query().Get("watch") == "true":
+			// an empty watch, which ends when the test is ready
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-endWatch
+		case first:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write(body)
+		}
+	}))
+	defer testServer.Close()
+
+	tracer := transport.NewInMemoryTracer()
+	c, err := RESTClientFor(&Config{
+		Host: testServer.URL,
+		ContentConfig: ContentConfig{
+			GroupVersion:         &api.Registry.GroupOrDie(api.GroupName).GroupVersion,
+			NegotiatedSerializer: api.Codecs,
+		},
+		RetryPolicy: retryServerErrorsOnce{},
+		Tracer:      tracer,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := c.Get().Namespace("ns").Resource("pods").Name("foo").Do().Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spans := tracer.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("expected a span for each of the 2 attempts and one for the request, got %#v", spans)
+	}
+	request := spans[2]
+	if request.Name != "GET pods" || request.Parent.IsValid() {
+		t.Errorf("unexpected request span: %#v", request)
+	}
+	expectedAttributes := map[string]interface{}{
+		"verb":             "GET",
+		"resource":         "pods",
+		"namespace":        "ns",
+		"name":             "foo",
+		"retries":          1,
+		"http.status_code": http.StatusOK,
+	}
+	if !reflect.DeepEqual(request.Attributes, expectedAttributes) {
+		t.Errorf("expected attributes %v, got %v", expectedAttributes, request.Attributes)
+	}
+	for i, attempt := range spans[:2] {
+		if attempt.Name != "HTTP GET" || attempt.Parent != request.SpanContext {
+			t.Errorf("expected attempt %d to be a child of the request span, got %#v", i, attempt)
+		}
+		if traceParents[i] != attempt.SpanContext.TraceParent() {
+			t.Errorf("expected attempt %d to send traceparent %q, got %q", i, attempt.SpanContext.TraceParent(), traceParents[i])
+		}
+	}
+	if code := spans[0].Attributes["http.status_code"]; code != http.StatusInternalServerError {
+		t.Errorf("expected the first attempt to record a 500, got %v", code)
+	}
+
+	tracer.Reset()
+	w, err := c.Get().Namespace("ns").Resource("pods").Param("watch", "true").Watch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the watch span is still open until the watch ends
+	spans = tracer.Spans()
+	if len(spans) != 1 || spans[0].Name != "HTTP GET" {
+		t.Errorf("expected only the attempt to have ended, got %#v", spans)
+	}
+	close(endWatch)
+	for range w.ResultChan() {
+	}
+	spans = tracer.Spans()
+	if len(spans) != 2 || spans[1].Name != "WATCH pods" || spans[1].Attributes["http.status_code"] != http.StatusOK {
+		t.Errorf("expected the watch span to end with the watch, got %#v", spans)
+	}
+}
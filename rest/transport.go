@@ -18,8 +18,10 @@ package rest
 
 import (
 	"crypto/tls"
+	"errors"
 	"net/http"
 
+	"github.com/lavalamp/client-go-flat/plugin/pkg/client/auth/exec"
 	"github.com/lavalamp/client-go-flat/transport"
 )
 
@@ -59,6 +61,9 @@ func HTTPWrappersForConfig(config *Config, rt http.RoundTripper) (http.RoundTrip
 // TransportConfig converts a client config to an appropriate transport config.
 func (c *Config) TransportConfig() (*transport.Config, error) {
 	wt := c.WrapTransport
+	if c.ExecProvider != nil && c.AuthProvider != nil {
+		return nil, errors.New("execProvider and authProvider cannot be used in combination")
+	}
 	if c.AuthProvider != nil {
 		provider, err := GetAuthProvider(c.Host, c.AuthProvider, c.AuthConfigPersister)
 		if err != nil {
@@ -73,10 +78,17 @@ func (c *Config) TransportConfig() (*transport.Config, error) {
 			wt = provider.WrapTransport
 		}
 	}
-	return &transport.Config{
-		UserAgent:     c.UserAgent,
-		Transport:     c.Transport,
-		WrapTransport: wt,
+	conf := &transport.Config{
+		UserAgent:       c.UserAgent,
+		Transport:       c.Transport,
+		WrapTransport:   wt,
+		Tracer:          c.Tracer,
+		RequestLogger:   c.RequestLogger,
+		TCPKeepAlive:    c.TCPKeepAlive,
+		ReadIdleTimeout: c.ReadIdleTimeout,
+		PingTimeout:     c.PingTimeout,
+		Proxy:           c.Proxy,
+		ProxyURL:        c.ProxyURL,
 		TLS: transport.TLSConfig{
 			CAFile:   c.CAFile,
 			CAData:   c.CAData,
@@ -86,13 +98,24 @@ func (c *Config) TransportConfig() (*transport.Config, error) {
 			KeyData:  c.KeyData,
 			Insecure: c.Insecure,
 		},
-		Username:    c.Username,
-		Password:    c.Password,
-		BearerToken: c.BearerToken,
+		Username:        c.Username,
+		Password:        c.Password,
+		BearerToken:     c.BearerToken,
+		BearerTokenFile: c.BearerTokenFile,
 		Impersonate: transport.ImpersonationConfig{
 			UserName: c.Impersonate.UserName,
 			Groups:   c.Impersonate.Groups,
 			Extra:    c.Impersonate.Extra,
 		},
-	}, nil
+	}
+	if c.ExecProvider != nil {
+		provider, err := exec.GetAuthenticator(c.ExecProvider)
+		if err != nil {
+			return nil, err
+		}
+		if err := provider.UpdateTransportConfig(conf); err != nil {
+			return nil, err
+		}
+	}
+	return conf, nil
 }
@@ -102,6 +102,12 @@ func (b *URLBackoff) CalculateBackoff(actualUrl *url.URL) time.Duration {
 	return b.Backoff.Get(b.baseUrlKey(actualUrl))
 }
 
+// inBackoff returns whether the backoff started by the last failure of
+// actualUrl has not expired yet.
+func (b *URLBackoff) inBackoff(actualUrl *url.URL) bool {
+	return b.Backoff.IsInBackOffSinceUpdate(b.baseUrlKey(actualUrl), b.Backoff.Clock.Now())
+}
+
 func (b *URLBackoff) Sleep(d time.Duration) {
 	b.Backoff.Clock.Sleep(d)
 }
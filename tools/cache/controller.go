@@ -50,6 +50,24 @@ type Config struct {
 	// queue.
 	FullResyncPeriod time.Duration
 
+	// ShouldResync, if specified, is invoked when the controller's reflector determines the next
+	// periodic sync should occur. If this returns true, it means the reflector should proceed with
+	// the resync.
+	ShouldResync ShouldResyncFunc
+
+	// WatchListPageSize, if non-zero, makes the controller's reflector break its
+	// initial list into pages of at most this many items.
+	WatchListPageSize int64
+
+	// Transform, if specified, is applied to every object received by the controller's
+	// reflector before it is added to the Queue.
+	Transform TransformFunc
+
+	// ResumeResourceVersion, if specified, is the resource version of the objects already
+	// in the Queue. The controller's reflector then starts watching from it instead of
+	// listing, and only lists if the resource version has expired.
+	ResumeResourceVersion string
+
 	// If true, when Process() returns an error, re-enqueue the object.
 	// TODO: add interface to let you inject a delay/backoff or drop
 	//       the object completely if desired. Pass the object in
@@ -57,9 +75,20 @@ type Config struct {
 	RetryOnError bool
 }
 
+// ShouldResyncFunc is a type of function that indicates if a reflector should perform a
+// resync or not. It can be used by a shared informer to support multiple event handlers with custom
+// resync periods.
+type ShouldResyncFunc func() bool
+
 // ProcessFunc processes a single object.
 type ProcessFunc func(obj interface{}) error
 
+// TransformFunc allows an object to be modified before it is stored. It can be used to
+// drop fields that are not needed in order to reduce the memory used by a cache. The
+// returned object must keep the namespace, name and resource version of the original,
+// and the function must not modify objects that it did not create.
+type TransformFunc func(obj interface{}) (interface{}, error)
+
 // Controller is a generic controller framework.
 type controller struct {
 	config         Config
@@ -86,12 +115,19 @@ func New(c *Config) Controller {
 // Run blocks; call via go.
 func (c *controller) Run(stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
+	var store Store = c.config.Queue
+	if c.config.Transform != nil {
+		store = &transformingStore{Store: store, transform: c.config.Transform}
+	}
 	r := NewReflector(
 		c.config.ListerWatcher,
 		c.config.ObjectType,
-		c.config.Queue,
+		store,
 		c.config.FullResyncPeriod,
 	)
+	r.ShouldResync = c.config.ShouldResync
+	r.WatchListPageSize = c.config.WatchListPageSize
+	r.resumeResourceVersion = c.config.ResumeResourceVersion
 
 	c.reflectorMutex.Lock()
 	c.reflector = r
@@ -102,12 +138,57 @@ func (c *controller) Run(stopCh <-chan struct{}) {
 	wait.Until(c.processLoop, time.Second, stopCh)
 }
 
+// transformingStore applies a TransformFunc to every object before passing it on to
+// the wrapped Store.
+type transformingStore struct {
+	Store
+	transform TransformFunc
+}
+
+func (t *transformingStore) Add(obj interface{}) error {
+	obj, err := t.transform(obj)
+	if err != nil {
+		return err
+	}
+	return t.Store.Add(obj)
+}
+
+func (t *transformingStore) Update(obj interface{}) error {
+	obj, err := t.transform(obj)
+	if err != nil {
+		return err
+	}
+	return t.Store.Update(obj)
+}
+
+func (t *transformingStore) Delete(obj interface{}) error {
+	obj, err := t.transform(obj)
+	if err != nil {
+		return err
+	}
+	return t.Store.Delete(obj)
+}
+
+func (t *transformingStore) Replace(list []interface{}, resourceVersion string) error {
+	transformed := make([]interface{}, 0, len(list))
+	for _, obj := range list {
+		obj, err := t.transform(obj)
+		if err != nil {
+			return err
+		}
+		transformed = append(transformed, obj)
+	}
+	return t.Store.Replace(transformed, resourceVersion)
+}
+
 // Returns true once this controller has completed an initial resource listing
 func (c *controller) HasSynced() bool {
 	return c.config.Queue.HasSynced()
 }
 
 func (c *controller) LastSyncResourceVersion() string {
+	c.reflectorMutex.RLock()
+	defer c.reflectorMutex.RUnlock()
 	if c.reflector == nil {
 		return ""
 	}
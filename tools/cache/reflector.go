@@ -17,12 +17,11 @@ limitations under the License.
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
-	"net"
-	"net/url"
 	"reflect"
 	"regexp"
 	goruntime "runtime"
@@ -30,7 +29,6 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/golang/glog"
@@ -41,6 +39,8 @@ import (
 	utilruntime "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/runtime"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/watch"
+	"github.com/lavalamp/client-go-flat/tools/pager"
+	"github.com/lavalamp/client-go-flat/util/flowcontrol"
 )
 
 // Reflector watches a specified resource and causes all changes to be reflected in the given store.
@@ -58,6 +58,12 @@ type Reflector struct {
 	// the beginning of the next one.
 	period       time.Duration
 	resyncPeriod time.Duration
+	// ShouldResync is invoked periodically and whenever it returns `true` the Store's Resync operation is invoked
+	ShouldResync func() bool
+	// WatchListPageSize, if non-zero, is the maximum number of items requested
+	// per page when listing. Paged lists are consistent reads: servers answer a
+	// list at resource version "0" from their watch cache and ignore the limit.
+	WatchListPageSize int64
 	// now() returns current time - exposed for testing purposes
 	now func() time.Time
 	// lastSyncResourceVersion is the resource version token last
@@ -66,6 +72,13 @@ type Reflector struct {
 	lastSyncResourceVersion string
 	// lastSyncResourceVersionMutex guards read/write access to lastSyncResourceVersion
 	lastSyncResourceVersionMutex sync.RWMutex
+	// resumeResourceVersion, if set, is the resource version of the store's initial
+	// contents. The first ListAndWatch watches from it instead of listing.
+	resumeResourceVersion string
+	// watchBackoff delays re-establishing a watch after it failed
+	watchBackoff *flowcontrol.Backoff
+	// metrics records lists and watches made by this reflector
+	metrics *reflectorMetrics
 }
 
 var (
@@ -74,6 +87,11 @@ var (
 	// However, it can be modified to avoid periodic resync to break the
 	// TCP connection.
 	minWatchTimeout = 5 * time.Minute
+
+	// Bounds of the exponential backoff between attempts to resume a failed
+	// watch from the last observed resource version.
+	minWatchBackoff = time.Second
+	maxWatchBackoff = 30 * time.Second
 )
 
 // NewNamespaceKeyedIndexerAndReflector creates an Indexer and a Reflector
@@ -104,6 +122,8 @@ func NewNamedReflector(name string, lw ListerWatcher, expectedType interface{},
 		period:        time.Second,
 		resyncPeriod:  resyncPeriod,
 		now:           time.Now,
+		watchBackoff:  flowcontrol.NewBackOff(minWatchBackoff, maxWatchBackoff),
+		metrics:       newReflectorMetrics(name),
 	}
 	return r
 }
@@ -236,27 +256,18 @@ func (r *Reflector) ListAndWatch(stopCh <-chan struct{}) error {
 	resyncCh, cleanup := r.resyncChan()
 	defer cleanup()
 
-	// Explicitly set "0" as resource version - it's fine for the List()
-	// to be served from cache and potentially be delayed relative to
-	// etcd contents. Reflector framework will catch up via Watch() eventually.
-	options := metav1.ListOptions{ResourceVersion: "0"}
-	list, err := r.listerWatcher.List(options)
-	if err != nil {
-		return fmt.Errorf("%s: Failed to list %v: %v", r.name, r.expectedType, err)
-	}
-	listMetaInterface, err := meta.ListAccessor(list)
-	if err != nil {
-		return fmt.Errorf("%s: Unable to understand list result %#v: %v", r.name, list, err)
-	}
-	resourceVersion = listMetaInterface.GetResourceVersion()
-	items, err := meta.ExtractList(list)
-	if err != nil {
-		return fmt.Errorf("%s: Unable to understand list result %#v (%v)", r.name, list, err)
-	}
-	if err := r.syncWith(items, resourceVersion); err != nil {
-		return fmt.Errorf("%s: Unable to sync list result: %v", r.name, err)
+	if resourceVersion = r.takeResumeResourceVersion(); len(resourceVersion) > 0 {
+		// The store already holds the contents at this resource version, so skip
+		// the list. If the version has expired the watch fails and we list again.
+		glog.V(3).Infof("%s: Resuming watch of %v at resource version %s", r.name, r.expectedType, resourceVersion)
+		r.setLastSyncResourceVersion(resourceVersion)
+	} else {
+		var err error
+		if resourceVersion, err = r.list(); err != nil {
+			return err
+		}
 	}
-	r.setLastSyncResourceVersion(resourceVersion)
+	r.watchBackoff.Reset(r.name)
 
 	resyncerrc := make(chan error, 1)
 	cancelCh := make(chan struct{})
@@ -270,10 +281,12 @@ func (r *Reflector) ListAndWatch(stopCh <-chan struct{}) error {
 			case <-cancelCh:
 				return
 			}
-			glog.V(4).Infof("%s: forcing resync", r.name)
-			if err := r.store.Resync(); err != nil {
-				resyncerrc <- err
-				return
+			if r.ShouldResync == nil || r.ShouldResync() {
+				glog.V(4).Infof("%s: forcing resync", r.name)
+				if err := r.store.Resync(); err != nil {
+					resyncerrc <- resyncError{err}
+					return
+				}
 			}
 			cleanup()
 			resyncCh, cleanup = r.resyncChan()
@@ -281,16 +294,24 @@ func (r *Reflector) ListAndWatch(stopCh <-chan struct{}) error {
 	}()
 
 	for {
+		select {
+		case <-stopCh:
+			return nil
+		default:
+		}
+
 		timemoutseconds := int64(minWatchTimeout.Seconds() * (rand.Float64() + 1.0))
-		options = metav1.ListOptions{
+		options := metav1.ListOptions{
 			ResourceVersion: resourceVersion,
 			// We want to avoid situations of hanging watchers. Stop any wachers that do not
 			// receive any events within the timeout window.
 			TimeoutSeconds: &timemoutseconds,
 		}
 
+		r.metrics.numberOfWatches.Inc()
 		w, err := r.listerWatcher.Watch(options)
 		if err != nil {
+			r.metrics.numberOfWatchErrors.Inc()
 			switch err {
 			case io.EOF:
 				// watch closed normally
@@ -299,28 +320,113 @@ func (r *Reflector) ListAndWatch(stopCh <-chan struct{}) error {
 			default:
 				utilruntime.HandleError(fmt.Errorf("%s: Failed to watch %v: %v", r.name, r.expectedType, err))
 			}
-			// If this is "connection refused" error, it means that most likely apiserver is not responsive.
-			// It doesn't make sense to re-list all objects because most likely we will be able to restart
-			// watch where we ended.
-			// If that's the case wait and resend watch request.
-			if urlError, ok := err.(*url.Error); ok {
-				if opError, ok := urlError.Err.(*net.OpError); ok {
-					if errno, ok := opError.Err.(syscall.Errno); ok && errno == syscall.ECONNREFUSED {
-						time.Sleep(time.Second)
-						continue
-					}
-				}
+			if isExpiredError(err) {
+				// The resource version is too old to watch from, only a new list can recover.
+				return nil
 			}
-			return nil
+			// The apiserver is most likely unreachable or overloaded. Re-listing all objects
+			// would only add to its load, so wait and resume the watch where we ended.
+			if !r.waitForWatchBackoff(stopCh) {
+				return nil
+			}
+			continue
 		}
 
 		if err := r.watchHandler(w, &resourceVersion, resyncerrc, stopCh); err != nil {
-			if err != errorStopRequested {
-				glog.Warningf("%s: watch of %v ended with: %v", r.name, r.expectedType, err)
+			if _, ok := err.(resyncError); ok {
+				utilruntime.HandleError(fmt.Errorf("%s: Failed to resync %v: %v", r.name, r.expectedType, err))
+				return nil
 			}
-			return nil
+			if err == errorStopRequested {
+				return nil
+			}
+			r.metrics.numberOfWatchErrors.Inc()
+			glog.Warningf("%s: watch of %v ended with: %v", r.name, r.expectedType, err)
+			if isExpiredError(err) {
+				return nil
+			}
+			if !r.waitForWatchBackoff(stopCh) {
+				return nil
+			}
+			continue
 		}
+		r.watchBackoff.Reset(r.name)
+	}
+}
+
+// resyncError wraps an error returned by the store while resyncing, so that it
+// can be told apart from errors of the watch.
+type resyncError struct {
+	error
+}
+
+// isExpiredError returns true if err means that the requested resource version
+// is no longer available and the reflector has to list again.
+func isExpiredError(err error) bool {
+	return apierrs.IsResourceExpired(err) || apierrs.IsGone(err)
+}
+
+// waitForWatchBackoff sleeps for the current watch backoff and moves it to the
+// next step. It returns false if stopCh was closed while waiting.
+func (r *Reflector) waitForWatchBackoff(stopCh <-chan struct{}) bool {
+	clock := r.watchBackoff.Clock
+	r.watchBackoff.Next(r.name, clock.Now())
+	delay := r.watchBackoff.Get(r.name)
+	glog.V(4).Infof("%s: resuming watch of %v in %v", r.name, r.expectedType, delay)
+	select {
+	case <-stopCh:
+		return false
+	case <-clock.After(delay):
+		return true
+	}
+}
+
+// list lists all items, replaces the contents of the store with them and returns
+// the resource version of the list.
+func (r *Reflector) list() (string, error) {
+	// Explicitly set "0" as resource version - it's fine for the List()
+	// to be served from cache and potentially be delayed relative to
+	// etcd contents. Reflector framework will catch up via Watch() eventually.
+	options := metav1.ListOptions{ResourceVersion: "0"}
+	var list runtime.Object
+	var err error
+	start := r.now()
+	if r.WatchListPageSize > 0 {
+		p := pager.New(pager.SimplePageFunc(r.listerWatcher.List))
+		p.PageSize = r.WatchListPageSize
+		options.ResourceVersion = ""
+		list, err = p.List(context.Background(), options)
+	} else {
+		list, err = r.listerWatcher.List(options)
 	}
+	if err != nil {
+		return "", fmt.Errorf("%s: Failed to list %v: %v", r.name, r.expectedType, err)
+	}
+	listMetaInterface, err := meta.ListAccessor(list)
+	if err != nil {
+		return "", fmt.Errorf("%s: Unable to understand list result %#v: %v", r.name, list, err)
+	}
+	resourceVersion := listMetaInterface.GetResourceVersion()
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return "", fmt.Errorf("%s: Unable to understand list result %#v (%v)", r.name, list, err)
+	}
+	r.metrics.list(start, len(items))
+	if err := r.syncWith(items, resourceVersion); err != nil {
+		return "", fmt.Errorf("%s: Unable to sync list result: %v", r.name, err)
+	}
+	r.setLastSyncResourceVersion(resourceVersion)
+	return resourceVersion, nil
+}
+
+// takeResumeResourceVersion returns the resource version set by the owner of the
+// store to skip the next list, and clears it so it is used at most once.
+func (r *Reflector) takeResumeResourceVersion() string {
+	r.lastSyncResourceVersionMutex.Lock()
+	defer r.lastSyncResourceVersionMutex.Unlock()
+	rv := r.resumeResourceVersion
+	r.resumeResourceVersion = ""
+	return rv
 }
 
 // syncWith replaces the store's items with the given list.
@@ -394,7 +500,9 @@ loop:
 	}
 
 	watchDuration := time.Now().Sub(start)
+	r.metrics.watch(start, eventCount)
 	if watchDuration < 1*time.Second && eventCount == 0 {
+		r.metrics.numberOfShortWatches.Inc()
 		glog.V(4).Infof("%s: Unexpected watch close - watch lasted less than a second and no items received", r.name)
 		return errors.New("very short watch")
 	}
@@ -414,4 +522,5 @@ func (r *Reflector) setLastSyncResourceVersion(v string) {
 	r.lastSyncResourceVersionMutex.Lock()
 	defer r.lastSyncResourceVersionMutex.Unlock()
 	r.lastSyncResourceVersion = v
+	r.metrics.resourceVersion(v)
 }
@@ -0,0 +1,149 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// This file provides abstractions for setting the provider (e.g., prometheus)
+// of metrics.
+
+// GaugeMetric represents a single numerical value that can arbitrarily go up
+// and down.
+type GaugeMetric interface {
+	Set(float64)
+}
+
+// CounterMetric represents a single numerical value that only ever
+// goes up.
+type CounterMetric interface {
+	Inc()
+}
+
+// SummaryMetric captures individual observations.
+type SummaryMetric interface {
+	Observe(float64)
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Set(float64)     {}
+func (noopMetric) Observe(float64) {}
+
+type reflectorMetrics struct {
+	// number of lists performed
+	numberOfLists CounterMetric
+	// how long a list takes, in seconds
+	listDuration SummaryMetric
+	// number of items returned by a list
+	numberOfItemsInList SummaryMetric
+
+	// number of watches started
+	numberOfWatches CounterMetric
+	// number of watches that failed to start or ended with an error
+	numberOfWatchErrors CounterMetric
+	// number of watches that ended quickly without delivering any events
+	numberOfShortWatches CounterMetric
+	// how long a watch lasted, in seconds
+	watchDuration SummaryMetric
+	// number of events delivered by a watch
+	numberOfItemsInWatch SummaryMetric
+
+	// last resource version observed by the reflector
+	lastResourceVersion GaugeMetric
+}
+
+func (m *reflectorMetrics) list(start time.Time, items int) {
+	m.numberOfLists.Inc()
+	m.listDuration.Observe(time.Since(start).Seconds())
+	m.numberOfItemsInList.Observe(float64(items))
+}
+
+func (m *reflectorMetrics) watch(start time.Time, events int) {
+	m.watchDuration.Observe(time.Since(start).Seconds())
+	m.numberOfItemsInWatch.Observe(float64(events))
+}
+
+func (m *reflectorMetrics) resourceVersion(rv string) {
+	// resource versions are opaque, but are integers in practice
+	if v, err := strconv.ParseUint(rv, 10, 64); err == nil {
+		m.lastResourceVersion.Set(float64(v))
+	}
+}
+
+// MetricsProvider generates various metrics used by the reflector.
+type MetricsProvider interface {
+	NewListsMetric(name string) CounterMetric
+	NewListDurationMetric(name string) SummaryMetric
+	NewItemsInListMetric(name string) SummaryMetric
+
+	NewWatchesMetric(name string) CounterMetric
+	NewWatchErrorsMetric(name string) CounterMetric
+	NewShortWatchesMetric(name string) CounterMetric
+	NewWatchDurationMetric(name string) SummaryMetric
+	NewItemsInWatchMetric(name string) SummaryMetric
+
+	NewLastResourceVersionMetric(name string) GaugeMetric
+}
+
+type noopMetricsProvider struct{}
+
+func (noopMetricsProvider) NewListsMetric(name string) CounterMetric         { return noopMetric{} }
+func (noopMetricsProvider) NewListDurationMetric(name string) SummaryMetric  { return noopMetric{} }
+func (noopMetricsProvider) NewItemsInListMetric(name string) SummaryMetric   { return noopMetric{} }
+func (noopMetricsProvider) NewWatchesMetric(name string) CounterMetric       { return noopMetric{} }
+func (noopMetricsProvider) NewWatchErrorsMetric(name string) CounterMetric   { return noopMetric{} }
+func (noopMetricsProvider) NewShortWatchesMetric(name string) CounterMetric  { return noopMetric{} }
+func (noopMetricsProvider) NewWatchDurationMetric(name string) SummaryMetric { return noopMetric{} }
+func (noopMetricsProvider) NewItemsInWatchMetric(name string) SummaryMetric  { return noopMetric{} }
+func (noopMetricsProvider) NewLastResourceVersionMetric(name string) GaugeMetric {
+	return noopMetric{}
+}
+
+var metricsFactory = struct {
+	metricsProvider MetricsProvider
+	setProviders    sync.Once
+}{
+	metricsProvider: noopMetricsProvider{},
+}
+
+func newReflectorMetrics(name string) *reflectorMetrics {
+	p := metricsFactory.metricsProvider
+	return &reflectorMetrics{
+		numberOfLists:        p.NewListsMetric(name),
+		listDuration:         p.NewListDurationMetric(name),
+		numberOfItemsInList:  p.NewItemsInListMetric(name),
+		numberOfWatches:      p.NewWatchesMetric(name),
+		numberOfWatchErrors:  p.NewWatchErrorsMetric(name),
+		numberOfShortWatches: p.NewShortWatchesMetric(name),
+		watchDuration:        p.NewWatchDurationMetric(name),
+		numberOfItemsInWatch: p.NewItemsInWatchMetric(name),
+		lastResourceVersion:  p.NewLastResourceVersionMetric(name),
+	}
+}
+
+// SetReflectorMetricsProvider sets the metrics provider used by reflectors
+// created after the call. Only the first call has any effect.
+func SetReflectorMetricsProvider(metricsProvider MetricsProvider) {
+	metricsFactory.setProviders.Do(func() {
+		metricsFactory.metricsProvider = metricsProvider
+	})
+}
@@ -20,15 +20,18 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
 
+	apierrs "github.com/lavalamp/client-go-flat/apimachinery/pkg/api/errors"
 	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/watch"
 	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	"github.com/lavalamp/client-go-flat/util/flowcontrol"
 )
 
 var nevererrc chan error
@@ -299,7 +302,7 @@ func TestReflectorListAndWatchWithErrors(t *testing.T) {
 			listErr: fmt.Errorf("a list error"),
 		}, {
 			list:     mkList("5", mkPod("bar", "3"), mkPod("qux", "5")),
-			watchErr: fmt.Errorf("a watch error"),
+			watchErr: apierrs.NewResourceExpired("a watch error"),
 		}, {
 			list: mkList("5", mkPod("bar", "3"), mkPod("qux", "5")),
 			events: []watch.Event{
@@ -335,7 +338,8 @@ func TestReflectorListAndWatchWithErrors(t *testing.T) {
 				if watchErr != nil {
 					return nil, watchErr
 				}
-				watchErr = fmt.Errorf("second watch")
+				// only an expired resource version makes the reflector list again
+				watchErr = apierrs.NewResourceExpired("second watch")
 				fw := watch.NewFake()
 				go func() {
 					for _, e := range watchRet {
@@ -354,6 +358,95 @@ func TestReflectorListAndWatchWithErrors(t *testing.T) {
 	}
 }
 
+type testCounter struct {
+	count int
+}
+
+func (c *testCounter) Inc() { c.count++ }
+
+func TestReflectorWatchResumesWithBackoff(t *testing.T) {
+	s := NewStore(MetaNamespaceKeyFunc)
+	lists := 0
+	var watchRVs []string
+	lw := &testLW{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			watchRVs = append(watchRVs, options.ResourceVersion)
+			switch len(watchRVs) {
+			case 1:
+				return nil, fmt.Errorf("connection refused")
+			case 2:
+				fw := watch.NewFakeWithChanSize(2, false)
+				fw.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "2"}})
+				fw.Error(&apierrs.NewInternalError(fmt.Errorf("etcd is unavailable")).ErrStatus)
+				return fw, nil
+			default:
+				fw := watch.NewFakeWithChanSize(1, false)
+				fw.Error(&apierrs.NewResourceExpired("too old resource version").ErrStatus)
+				return fw, nil
+			}
+		},
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			lists++
+			return &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}}, nil
+		},
+	}
+	r := NewReflector(lw, &v1.Pod{}, s, 0)
+	r.watchBackoff = flowcontrol.NewBackOff(time.Millisecond, 10*time.Millisecond)
+	listsMetric, watches, watchErrors := &testCounter{}, &testCounter{}, &testCounter{}
+	r.metrics.numberOfLists = listsMetric
+	r.metrics.numberOfWatches = watches
+	r.metrics.numberOfWatchErrors = watchErrors
+
+	if err := r.ListAndWatch(wait.NeverStop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lists != 1 {
+		t.Errorf("expected a single list, got %d", lists)
+	}
+	if e, a := []string{"1", "1", "2"}, watchRVs; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected watches from resource versions %v, got %v", e, a)
+	}
+	if _, exists, _ := s.GetByKey("foo"); !exists {
+		t.Errorf("expected the watched pod in the store")
+	}
+	if listsMetric.count != 1 || watches.count != 3 || watchErrors.count != 3 {
+		t.Errorf("unexpected metrics: lists %d, watches %d, watch errors %d", listsMetric.count, watches.count, watchErrors.count)
+	}
+}
+
+func TestReflectorWatchBackoffStop(t *testing.T) {
+	stopCh := make(chan struct{})
+	watches := 0
+	lw := &testLW{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			watches++
+			if watches == 3 {
+				close(stopCh)
+			}
+			return nil, fmt.Errorf("connection refused")
+		},
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}}, nil
+		},
+	}
+	r := NewReflector(lw, &v1.Pod{}, NewStore(MetaNamespaceKeyFunc), 0)
+	r.watchBackoff = flowcontrol.NewBackOff(time.Millisecond, 10*time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		r.ListAndWatch(stopCh)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("reflector did not stop while backing off")
+	}
+	if watches != 3 {
+		t.Errorf("expected 3 watch attempts, got %d", watches)
+	}
+}
+
 func TestReflectorResync(t *testing.T) {
 	iteration := 0
 	stopCh := make(chan struct{})
@@ -387,3 +480,47 @@ func TestReflectorResync(t *testing.T) {
 		t.Errorf("exactly 2 iterations were expected, got: %v", iteration)
 	}
 }
+
+func TestReflectorListPaging(t *testing.T) {
+	stopCh := make(chan struct{})
+	s := NewStore(MetaNamespaceKeyFunc)
+	var requests []metav1.ListOptions
+	lw := &testLW{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			if options.ResourceVersion != "10" {
+				t.Errorf("expected watch from the list resource version, got %q", options.ResourceVersion)
+			}
+			// Stop the reflector once the list has been synced.
+			close(stopCh)
+			return watch.NewFake(), nil
+		},
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			requests = append(requests, options)
+			start := len(requests) - 1
+			list := &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}
+			list.Items = append(list.Items, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", start)}})
+			if start < 2 {
+				list.Continue = strconv.Itoa(start + 1)
+			}
+			return list, nil
+		},
+	}
+	r := NewReflector(lw, &v1.Pod{}, s, 0)
+	r.WatchListPageSize = 1
+	r.ListAndWatch(stopCh)
+
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 list requests, got %d", len(requests))
+	}
+	for i, options := range requests {
+		if options.Limit != 1 || options.ResourceVersion != "" {
+			t.Errorf("request %d: unexpected options %#v", i, options)
+		}
+	}
+	if len(s.List()) != 3 {
+		t.Errorf("expected 3 items in the store, got %d", len(s.List()))
+	}
+	if rv := r.LastSyncResourceVersion(); rv != "10" {
+		t.Errorf("expected last sync resource version 10, got %q", rv)
+	}
+}
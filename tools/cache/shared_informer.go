@@ -18,12 +18,15 @@ package cache
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/api/meta"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
 	utilruntime "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/runtime"
 	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/util/clock"
 
 	"github.com/golang/glog"
 )
@@ -37,10 +40,20 @@ import (
 // to share a common cache across many controllers. Extending the broadcaster
 // would have required us keep duplicate caches for each watch.
 type SharedInformer interface {
-	// events to a single handler are delivered sequentially, but there is no coordination between different handlers
-	// You may NOT add a handler *after* the SharedInformer is running.  That will result in an error being returned.
-	// TODO we should try to remove this restriction eventually.
+	// AddEventHandler adds an event handler to the shared informer using the shared informer's resync
+	// period.  Events to a single handler are delivered sequentially, but there is no coordination
+	// between different handlers.  A handler added after the informer has started first receives
+	// synthetic adds for everything already in the store.
 	AddEventHandler(handler ResourceEventHandler) error
+	// AddEventHandlerWithResyncPeriod adds an event handler to the shared informer using the
+	// specified resync period.  Events to a single handler are delivered sequentially, but there is
+	// no coordination between different handlers.  The returned registration can be passed to
+	// RemoveEventHandler to stop delivering events to the handler.
+	AddEventHandlerWithResyncPeriod(handler ResourceEventHandler, resyncPeriod time.Duration) (ResourceEventHandlerRegistration, error)
+	// RemoveEventHandler stops delivering events to the handler identified by handle and releases
+	// its pending notifications.  It returns an error if handle was not returned by this informer
+	// or has already been removed.
+	RemoveEventHandler(handle ResourceEventHandlerRegistration) error
 	GetStore() Store
 	// GetController gives back a synthetic interface that "votes" to start the informer
 	GetController() Controller
@@ -49,31 +62,45 @@ type SharedInformer interface {
 	LastSyncResourceVersion() string
 }
 
+// ResourceEventHandlerRegistration is the handle returned by AddEventHandlerWithResyncPeriod.
+type ResourceEventHandlerRegistration interface {
+	// ResyncPeriod returns the resync period in effect for the handler.  It may differ from the
+	// requested period if the informer had to align it with its own resync check period.
+	ResyncPeriod() time.Duration
+}
+
 type SharedIndexInformer interface {
 	SharedInformer
 	// AddIndexers add indexers to the informer before it starts.
 	AddIndexers(indexers Indexers) error
 	GetIndexer() Indexer
+	// SetTransform sets a function applied to every object before it enters the informer's
+	// queue and indexer.  It must be called before the informer starts.
+	SetTransform(transform TransformFunc) error
+	// SetSnapshot makes the informer restore its cache from a snapshot file when it starts
+	// and save the cache to that file while it runs.  It must be called before the informer
+	// starts.
+	SetSnapshot(config SnapshotConfig) error
 }
 
 // NewSharedInformer creates a new instance for the listwatcher.
-// TODO: create a cache/factory of these at a higher level for the list all, watch all of a given resource that can
-// be shared amongst all consumers.
 func NewSharedInformer(lw ListerWatcher, objType runtime.Object, resyncPeriod time.Duration) SharedInformer {
 	return NewSharedIndexInformer(lw, objType, resyncPeriod, Indexers{})
 }
 
 // NewSharedIndexInformer creates a new instance for the listwatcher.
-// TODO: create a cache/factory of these at a higher level for the list all, watch all of a given resource that can
-// be shared amongst all consumers.
-func NewSharedIndexInformer(lw ListerWatcher, objType runtime.Object, resyncPeriod time.Duration, indexers Indexers) SharedIndexInformer {
+// defaultEventHandlerResyncPeriod is the resync period used by handlers added with AddEventHandler.
+func NewSharedIndexInformer(lw ListerWatcher, objType runtime.Object, defaultEventHandlerResyncPeriod time.Duration, indexers Indexers) SharedIndexInformer {
+	realClock := &clock.RealClock{}
 	sharedIndexInformer := &sharedIndexInformer{
-		processor:             &sharedProcessor{},
-		indexer:               NewIndexer(DeletionHandlingMetaNamespaceKeyFunc, indexers),
-		listerWatcher:         lw,
-		objectType:            objType,
-		fullResyncPeriod:      resyncPeriod,
-		cacheMutationDetector: NewCacheMutationDetector(fmt.Sprintf("%T", objType)),
+		processor:                       &sharedProcessor{clock: realClock},
+		indexer:                         NewIndexer(DeletionHandlingMetaNamespaceKeyFunc, indexers),
+		listerWatcher:                   lw,
+		objectType:                      objType,
+		resyncCheckPeriod:               defaultEventHandlerResyncPeriod,
+		defaultEventHandlerResyncPeriod: defaultEventHandlerResyncPeriod,
+		cacheMutationDetector:           NewCacheMutationDetector(fmt.Sprintf("%T", objType)),
+		clock:                           realClock,
 	}
 	return sharedIndexInformer
 }
@@ -114,9 +141,23 @@ type sharedIndexInformer struct {
 	cacheMutationDetector CacheMutationDetector
 
 	// This block is tracked to handle late initialization of the controller
-	listerWatcher    ListerWatcher
-	objectType       runtime.Object
-	fullResyncPeriod time.Duration
+	listerWatcher ListerWatcher
+	objectType    runtime.Object
+
+	// resyncCheckPeriod is how often we want the reflector's resync timer to fire so it can call
+	// shouldResync to check if any of our listeners need a resync.
+	resyncCheckPeriod time.Duration
+	// defaultEventHandlerResyncPeriod is the default resync period for any handlers added via
+	// AddEventHandler (i.e. they don't specify one and just want to use the shared informer's default
+	// value).
+	defaultEventHandlerResyncPeriod time.Duration
+	// clock allows for testability
+	clock clock.Clock
+
+	// transform is applied to objects before they are queued, see SetTransform
+	transform TransformFunc
+	// snapshot configures saving and restoring the cache, see SetSnapshot
+	snapshot *SnapshotConfig
 
 	started     bool
 	startedLock sync.Mutex
@@ -171,11 +212,16 @@ func (s *sharedIndexInformer) Run(stopCh <-chan struct{}) {
 		Queue:            fifo,
 		ListerWatcher:    s.listerWatcher,
 		ObjectType:       s.objectType,
-		FullResyncPeriod: s.fullResyncPeriod,
+		FullResyncPeriod: s.resyncCheckPeriod,
 		RetryOnError:     false,
+		ShouldResync:     s.processor.shouldResync,
+		Transform:        s.transform,
 
 		Process: s.HandleDeltas,
 	}
+	if s.snapshot != nil {
+		cfg.ResumeResourceVersion = s.restoreSnapshot(fifo)
+	}
 
 	func() {
 		s.startedLock.Lock()
@@ -188,9 +234,60 @@ func (s *sharedIndexInformer) Run(stopCh <-chan struct{}) {
 	s.stopCh = stopCh
 	s.cacheMutationDetector.Run(stopCh)
 	s.processor.run(stopCh)
+	if s.snapshot != nil {
+		go s.runSnapshots(fifo, stopCh)
+	}
 	s.controller.Run(stopCh)
 }
 
+// restoreSnapshot queues the objects saved in the snapshot file and returns the resource
+// version they were saved at, or "" if there is no usable snapshot.
+func (s *sharedIndexInformer) restoreSnapshot(fifo *DeltaFIFO) string {
+	objs, resourceVersion, err := readSnapshot(s.snapshot.Path, s.snapshot.Codec, s.objectType)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			utilruntime.HandleError(fmt.Errorf("unable to restore cache of %T from %s: %v", s.objectType, s.snapshot.Path, err))
+		}
+		return ""
+	}
+	if err := fifo.Replace(objs, resourceVersion); err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to restore cache of %T from %s: %v", s.objectType, s.snapshot.Path, err))
+		return ""
+	}
+	glog.V(2).Infof("Restored %d objects of %T at resource version %s from %s", len(objs), s.objectType, resourceVersion, s.snapshot.Path)
+	return resourceVersion
+}
+
+// runSnapshots saves the cache every snapshot period and once more when stopCh is closed.
+func (s *sharedIndexInformer) runSnapshots(fifo *DeltaFIFO, stopCh <-chan struct{}) {
+	if s.snapshot.Period > 0 {
+		wait.Until(func() { s.saveSnapshot(fifo) }, s.snapshot.Period, stopCh)
+	} else {
+		<-stopCh
+	}
+	s.saveSnapshot(fifo)
+}
+
+// saveSnapshot writes the indexer contents to the snapshot file. The resource version is
+// read before checking that no change is waiting to be applied to the indexer, so the
+// saved objects are at least as fresh as the saved resource version and watching from it
+// replays anything newer.
+func (s *sharedIndexInformer) saveSnapshot(fifo *DeltaFIFO) {
+	resourceVersion := s.LastSyncResourceVersion()
+	if len(resourceVersion) == 0 {
+		return
+	}
+	// DeltaFIFO holds its lock while a popped item is processed, so an empty queue
+	// also means that no change is being applied to the indexer right now.
+	if len(fifo.ListKeys()) > 0 {
+		glog.V(4).Infof("Skipping snapshot of %T, changes are still queued", s.objectType)
+		return
+	}
+	if err := writeSnapshot(s.snapshot.Path, s.snapshot.Codec, resourceVersion, s.indexer.List()); err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to save cache of %T to %s: %v", s.objectType, s.snapshot.Path, err))
+	}
+}
+
 func (s *sharedIndexInformer) isStarted() bool {
 	s.startedLock.Lock()
 	defer s.startedLock.Unlock()
@@ -236,18 +333,87 @@ func (s *sharedIndexInformer) AddIndexers(indexers Indexers) error {
 	return s.indexer.AddIndexers(indexers)
 }
 
+func (s *sharedIndexInformer) SetTransform(transform TransformFunc) error {
+	s.startedLock.Lock()
+	defer s.startedLock.Unlock()
+
+	if s.started {
+		return fmt.Errorf("informer has already started")
+	}
+
+	s.transform = transform
+	return nil
+}
+
+func (s *sharedIndexInformer) SetSnapshot(config SnapshotConfig) error {
+	s.startedLock.Lock()
+	defer s.startedLock.Unlock()
+
+	if s.started {
+		return fmt.Errorf("informer has already started")
+	}
+	if len(config.Path) == 0 || config.Codec == nil {
+		return fmt.Errorf("a snapshot requires a path and a codec")
+	}
+
+	s.snapshot = &config
+	return nil
+}
+
 func (s *sharedIndexInformer) GetController() Controller {
 	return &dummyController{informer: s}
 }
 
 func (s *sharedIndexInformer) AddEventHandler(handler ResourceEventHandler) error {
+	_, err := s.AddEventHandlerWithResyncPeriod(handler, s.defaultEventHandlerResyncPeriod)
+	return err
+}
+
+// minimumResyncPeriod is the smallest resync period a handler may request.
+const minimumResyncPeriod = 1 * time.Second
+
+func determineResyncPeriod(desired, check time.Duration) time.Duration {
+	if desired == 0 {
+		return desired
+	}
+	if check == 0 {
+		glog.Warningf("The specified resyncPeriod %v is invalid because this shared informer doesn't support resyncing", desired)
+		return 0
+	}
+	if desired < check {
+		glog.Warningf("The specified resyncPeriod %v is being increased to the minimum resyncCheckPeriod %v", desired, check)
+		return check
+	}
+	return desired
+}
+
+func (s *sharedIndexInformer) AddEventHandlerWithResyncPeriod(handler ResourceEventHandler, resyncPeriod time.Duration) (ResourceEventHandlerRegistration, error) {
 	s.startedLock.Lock()
 	defer s.startedLock.Unlock()
 
+	if resyncPeriod > 0 {
+		if resyncPeriod < minimumResyncPeriod {
+			glog.Warningf("resyncPeriod %v is too small. Changing it to the minimum allowed value of %v", resyncPeriod, minimumResyncPeriod)
+			resyncPeriod = minimumResyncPeriod
+		}
+
+		if s.resyncCheckPeriod == 0 || resyncPeriod < s.resyncCheckPeriod {
+			if s.started {
+				glog.Warningf("resyncPeriod %v is smaller than resyncCheckPeriod %v and the informer has already started. Changing it to %v", resyncPeriod, s.resyncCheckPeriod, s.resyncCheckPeriod)
+			} else {
+				// the reflector has not been built yet, so we can still make its resync timer fire
+				// often enough for this handler; the other listeners keep their own periods.
+				s.resyncCheckPeriod = resyncPeriod
+				s.processor.resyncCheckPeriodChanged(resyncPeriod)
+			}
+		}
+	}
+
+	listener := newProcessListener(handler, resyncPeriod, determineResyncPeriod(resyncPeriod, s.resyncCheckPeriod), s.clock.Now())
+
 	if !s.started {
-		listener := newProcessListener(handler)
-		s.processor.listeners = append(s.processor.listeners, listener)
-		return nil
+		s.processor.addListener(listener)
+		return listener, nil
 	}
 
 	// in order to safely join, we have to
@@ -258,8 +424,7 @@ func (s *sharedIndexInformer) AddEventHandler(handler ResourceEventHandler) erro
 	s.blockDeltas.Lock()
 	defer s.blockDeltas.Unlock()
 
-	listener := newProcessListener(handler)
-	s.processor.listeners = append(s.processor.listeners, listener)
+	s.processor.addListener(listener)
 
 	go listener.run(s.stopCh)
 	go listener.pop(s.stopCh)
@@ -269,6 +434,25 @@ func (s *sharedIndexInformer) AddEventHandler(handler ResourceEventHandler) erro
 		listener.add(addNotification{newObj: items[i]})
 	}
 
+	return listener, nil
+}
+
+func (s *sharedIndexInformer) RemoveEventHandler(handle ResourceEventHandlerRegistration) error {
+	s.startedLock.Lock()
+	defer s.startedLock.Unlock()
+
+	listener, ok := handle.(*processorListener)
+	if !ok {
+		return fmt.Errorf("unrecognized event handler registration %#v", handle)
+	}
+
+	// block deltas so that no notification is in the middle of being handed to the listener
+	s.blockDeltas.Lock()
+	defer s.blockDeltas.Unlock()
+
+	if !s.processor.removeListener(listener) {
+		return fmt.Errorf("event handler registration is not registered with this informer")
+	}
 	return nil
 }
 
@@ -285,40 +469,142 @@ func (s *sharedIndexInformer) HandleDeltas(obj interface{}) error {
 				if err := s.indexer.Update(d.Object); err != nil {
 					return err
 				}
-				s.processor.distribute(updateNotification{oldObj: old, newObj: d.Object})
+				s.processor.distribute(updateNotification{oldObj: old, newObj: d.Object}, isResync(d, old))
 			} else {
 				if err := s.indexer.Add(d.Object); err != nil {
 					return err
 				}
-				s.processor.distribute(addNotification{newObj: d.Object})
+				s.processor.distribute(addNotification{newObj: d.Object}, false)
 			}
 		case Deleted:
 			if err := s.indexer.Delete(d.Object); err != nil {
 				return err
 			}
-			s.processor.distribute(deleteNotification{oldObj: d.Object})
+			s.processor.distribute(deleteNotification{oldObj: d.Object}, false)
 		}
 	}
 	return nil
 }
 
+// isResync returns true if the Sync delta d only replays old, the object already in the store.
+// Sync deltas are produced both by periodic resyncs and by relists; a relist that brings a
+// newer resourceVersion is a real change that every listener must see.
+func isResync(d Delta, old interface{}) bool {
+	if d.Type != Sync {
+		return false
+	}
+	newMeta, err := meta.Accessor(d.Object)
+	if err != nil {
+		return true
+	}
+	oldMeta, err := meta.Accessor(old)
+	if err != nil {
+		return true
+	}
+	return newMeta.GetResourceVersion() == oldMeta.GetResourceVersion()
+}
+
 type sharedProcessor struct {
-	listeners []*processorListener
+	listenersLock sync.RWMutex
+	listeners     []*processorListener
+	// syncingListeners are the listeners whose resync period elapsed at the last resync check.
+	// Resync notifications are only delivered to them.
+	syncingListeners []*processorListener
+	clock            clock.Clock
 }
 
-func (p *sharedProcessor) distribute(obj interface{}) {
-	for _, listener := range p.listeners {
-		listener.add(obj)
+func (p *sharedProcessor) addListener(listener *processorListener) {
+	p.listenersLock.Lock()
+	defer p.listenersLock.Unlock()
+
+	p.listeners = append(p.listeners, listener)
+	p.syncingListeners = append(p.syncingListeners, listener)
+}
+
+// removeListener removes listener and stops its goroutines.  It returns false if listener
+// was not registered.
+func (p *sharedProcessor) removeListener(listener *processorListener) bool {
+	p.listenersLock.Lock()
+	defer p.listenersLock.Unlock()
+
+	found := false
+	for i := range p.listeners {
+		if p.listeners[i] == listener {
+			p.listeners = append(p.listeners[:i], p.listeners[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	for i := range p.syncingListeners {
+		if p.syncingListeners[i] == listener {
+			p.syncingListeners = append(p.syncingListeners[:i], p.syncingListeners[i+1:]...)
+			break
+		}
+	}
+	listener.remove()
+	return true
+}
+
+func (p *sharedProcessor) distribute(obj interface{}, sync bool) {
+	p.listenersLock.RLock()
+	defer p.listenersLock.RUnlock()
+
+	if sync {
+		for _, listener := range p.syncingListeners {
+			listener.add(obj)
+		}
+	} else {
+		for _, listener := range p.listeners {
+			listener.add(obj)
+		}
 	}
 }
 
 func (p *sharedProcessor) run(stopCh <-chan struct{}) {
+	p.listenersLock.RLock()
+	defer p.listenersLock.RUnlock()
+
 	for _, listener := range p.listeners {
 		go listener.run(stopCh)
 		go listener.pop(stopCh)
 	}
 }
 
+// shouldResync queries every listener to determine if any of them need a resync, based on each
+// listener's resyncPeriod.
+func (p *sharedProcessor) shouldResync() bool {
+	p.listenersLock.Lock()
+	defer p.listenersLock.Unlock()
+
+	p.syncingListeners = []*processorListener{}
+
+	resyncNeeded := false
+	now := p.clock.Now()
+	for _, listener := range p.listeners {
+		// need to loop through all the listeners to see if they need to resync so we can prepare any
+		// listeners that are going to be resyncing.
+		if listener.shouldResync(now) {
+			resyncNeeded = true
+			p.syncingListeners = append(p.syncingListeners, listener)
+			listener.determineNextResync(now)
+		}
+	}
+	return resyncNeeded
+}
+
+func (p *sharedProcessor) resyncCheckPeriodChanged(resyncCheckPeriod time.Duration) {
+	p.listenersLock.RLock()
+	defer p.listenersLock.RUnlock()
+
+	for _, listener := range p.listeners {
+		resyncPeriod := determineResyncPeriod(listener.requestedResyncPeriod, resyncCheckPeriod)
+		listener.setResyncPeriod(resyncPeriod)
+	}
+}
+
 type processorListener struct {
 	// lock/cond protects access to 'pendingNotifications'.
 	lock sync.RWMutex
@@ -334,19 +620,46 @@ type processorListener struct {
 	nextCh chan interface{}
 
 	handler ResourceEventHandler
+
+	// removedCh is closed when the listener is removed from its sharedProcessor, which stops
+	// pop and run independently of the informer's stop channel.
+	removedCh chan struct{}
+
+	// requestedResyncPeriod is how frequently the listener wants a full resync from the shared informer
+	requestedResyncPeriod time.Duration
+	// resyncPeriod is how frequently the listener wants a full resync from the shared informer. This
+	// value may differ from requestedResyncPeriod if the shared informer adjusts it to align with the
+	// informer's overall resync check period.
+	resyncPeriod time.Duration
+	// nextResync is the earliest time the listener should get a full resync
+	nextResync time.Time
+	// resyncLock guards access to resyncPeriod and nextResync
+	resyncLock sync.Mutex
 }
 
-func newProcessListener(handler ResourceEventHandler) *processorListener {
+func newProcessListener(handler ResourceEventHandler, requestedResyncPeriod, resyncPeriod time.Duration, now time.Time) *processorListener {
 	ret := &processorListener{
-		pendingNotifications: []interface{}{},
-		nextCh:               make(chan interface{}),
-		handler:              handler,
+		pendingNotifications:  []interface{}{},
+		nextCh:                make(chan interface{}),
+		handler:               handler,
+		removedCh:             make(chan struct{}),
+		requestedResyncPeriod: requestedResyncPeriod,
+		resyncPeriod:          resyncPeriod,
 	}
 
 	ret.cond.L = &ret.lock
+	ret.determineNextResync(now)
 	return ret
 }
 
+// ResyncPeriod returns the resync period in effect for the listener.
+func (p *processorListener) ResyncPeriod() time.Duration {
+	p.resyncLock.Lock()
+	defer p.resyncLock.Unlock()
+
+	return p.resyncPeriod
+}
+
 func (p *processorListener) add(notification interface{}) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -355,6 +668,16 @@ func (p *processorListener) add(notification interface{}) {
 	p.cond.Broadcast()
 }
 
+// remove stops the listener and drops its pending notifications.
+func (p *processorListener) remove() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	close(p.removedCh)
+	p.pendingNotifications = nil
+	p.cond.Broadcast()
+}
+
 func (p *processorListener) pop(stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 
@@ -364,10 +687,12 @@ func (p *processorListener) pop(stopCh <-chan struct{}) {
 			defer p.lock.Unlock()
 
 			for len(p.pendingNotifications) == 0 {
-				// check if we're shutdown
+				// check if we're shutdown or removed
 				select {
 				case <-stopCh:
 					return nil, true
+				case <-p.removedCh:
+					return nil, true
 				default:
 				}
 				p.cond.Wait()
@@ -386,6 +711,8 @@ func (p *processorListener) pop(stopCh <-chan struct{}) {
 		select {
 		case <-stopCh:
 			return
+		case <-p.removedCh:
+			return
 		case p.nextCh <- notification:
 		}
 	}
@@ -404,6 +731,8 @@ func (p *processorListener) run(stopCh <-chan struct{}) {
 				p.cond.Broadcast()
 			}()
 			return
+		case <-p.removedCh:
+			return
 		case next = <-p.nextCh:
 		}
 
@@ -419,3 +748,30 @@ func (p *processorListener) run(stopCh <-chan struct{}) {
 		}
 	}
 }
+
+// shouldResync determines if the listener needs a resync. If the listener's resyncPeriod is 0,
+// this always returns false.
+func (p *processorListener) shouldResync(now time.Time) bool {
+	p.resyncLock.Lock()
+	defer p.resyncLock.Unlock()
+
+	if p.resyncPeriod == 0 {
+		return false
+	}
+
+	return now.After(p.nextResync) || now.Equal(p.nextResync)
+}
+
+func (p *processorListener) determineNextResync(now time.Time) {
+	p.resyncLock.Lock()
+	defer p.resyncLock.Unlock()
+
+	p.nextResync = now.Add(p.resyncPeriod)
+}
+
+func (p *processorListener) setResyncPeriod(resyncPeriod time.Duration) {
+	p.resyncLock.Lock()
+	defer p.resyncLock.Unlock()
+
+	p.resyncPeriod = resyncPeriod
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/sets"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	fcache "github.com/lavalamp/client-go-flat/tools/cache/testing"
+	"github.com/lavalamp/client-go-flat/util/clock"
+)
+
+type testListener struct {
+	lock              sync.RWMutex
+	resyncPeriod      time.Duration
+	expectedItemNames sets.String
+	receivedItemNames []string
+	name              string
+}
+
+func newTestListener(name string, resyncPeriod time.Duration, expected ...string) *testListener {
+	l := &testListener{
+		resyncPeriod:      resyncPeriod,
+		expectedItemNames: sets.NewString(expected...),
+		name:              name,
+	}
+	return l
+}
+
+func (l *testListener) OnAdd(obj interface{}) {
+	l.handle(obj)
+}
+
+func (l *testListener) OnUpdate(old, new interface{}) {
+	l.handle(new)
+}
+
+func (l *testListener) OnDelete(obj interface{}) {
+}
+
+func (l *testListener) handle(obj interface{}) {
+	pod := obj.(*v1.Pod)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.receivedItemNames = append(l.receivedItemNames, pod.Name)
+}
+
+func (l *testListener) ok() bool {
+	err := wait.PollImmediate(100*time.Millisecond, 2*time.Second, func() (bool, error) {
+		if l.satisfiedExpectations() {
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return false
+	}
+
+	// wait just a bit to allow any unexpected stragglers to come in
+	time.Sleep(50 * time.Millisecond)
+	return l.satisfiedExpectations()
+}
+
+func (l *testListener) satisfiedExpectations() bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return len(l.receivedItemNames) == l.expectedItemNames.Len() && sets.NewString(l.receivedItemNames...).Equal(l.expectedItemNames)
+}
+
+func (l *testListener) received() []string {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	names := append([]string{}, l.receivedItemNames...)
+	sort.Strings(names)
+	return names
+}
+
+func (l *testListener) reset() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.receivedItemNames = []string{}
+}
+
+func TestListenerResyncPeriods(t *testing.T) {
+	// source simulates an apiserver object endpoint.
+	source := fcache.NewFakeControllerSource()
+	source.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}})
+	source.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}})
+
+	// create the shared informer and resync every 1s
+	informer := NewSharedInformer(source, &v1.Pod{}, 1*time.Second).(*sharedIndexInformer)
+
+	clock := clock.NewFakeClock(time.Now())
+	informer.clock = clock
+	informer.processor.clock = clock
+
+	// listener 1, never resync
+	listener1 := newTestListener("listener1", 0, "pod1", "pod2")
+	informer.AddEventHandlerWithResyncPeriod(listener1, listener1.resyncPeriod)
+
+	// listener 2, resync every 2s
+	listener2 := newTestListener("listener2", 2*time.Second, "pod1", "pod2")
+	informer.AddEventHandlerWithResyncPeriod(listener2, listener2.resyncPeriod)
+
+	// listener 3, resync every 3s
+	listener3 := newTestListener("listener3", 3*time.Second, "pod1", "pod2")
+	informer.AddEventHandlerWithResyncPeriod(listener3, listener3.resyncPeriod)
+	listeners := []*testListener{listener1, listener2, listener3}
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go informer.Run(stop)
+
+	// ensure all listeners got the initial List
+	for _, listener := range listeners {
+		if !listener.ok() {
+			t.Errorf("%s: expected %v, got %v", listener.name, listener.expectedItemNames, listener.received())
+		}
+	}
+
+	for _, listener := range listeners {
+		listener.reset()
+	}
+
+	// advance so listener2 gets a resync
+	clock.Step(2 * time.Second)
+
+	// make sure listener2 got the resync
+	if !listener2.ok() {
+		t.Errorf("%s: expected %v, got %v", listener2.name, listener2.expectedItemNames, listener2.received())
+	}
+
+	// wait a bit to give errant items a chance to go to 1 and 3
+	time.Sleep(1 * time.Second)
+
+	// make sure listeners 1 and 3 got nothing
+	if len(listener1.received()) != 0 {
+		t.Errorf("listener1: should not have resynced (got %d)", len(listener1.received()))
+	}
+	if len(listener3.received()) != 0 {
+		t.Errorf("listener3: should not have resynced (got %d)", len(listener3.received()))
+	}
+
+	for _, listener := range listeners {
+		listener.reset()
+	}
+
+	// advance so listener3 gets a resync
+	clock.Step(1 * time.Second)
+
+	// make sure listener3 got the resync
+	if !listener3.ok() {
+		t.Errorf("%s: expected %v, got %v", listener3.name, listener3.expectedItemNames, listener3.received())
+	}
+
+	// wait a bit to give errant items a chance to go to 1 and 2
+	time.Sleep(1 * time.Second)
+
+	// make sure listeners 1 and 2 got nothing
+	if len(listener1.received()) != 0 {
+		t.Errorf("listener1: should not have resynced (got %d)", len(listener1.received()))
+	}
+	if len(listener2.received()) != 0 {
+		t.Errorf("listener2: should not have resynced (got %d)", len(listener2.received()))
+	}
+}
+
+func TestResyncCheckPeriod(t *testing.T) {
+	// source simulates an apiserver object endpoint.
+	source := fcache.NewFakeControllerSource()
+
+	// create the shared informer and resync every 12 hours
+	informer := NewSharedInformer(source, &v1.Pod{}, 12*time.Hour).(*sharedIndexInformer)
+
+	clock := clock.NewFakeClock(time.Now())
+	informer.clock = clock
+	informer.processor.clock = clock
+
+	// listener 1, never resync
+	listener1 := newTestListener("listener1", 0)
+	informer.AddEventHandlerWithResyncPeriod(listener1, listener1.resyncPeriod)
+	if e, a := 12*time.Hour, informer.resyncCheckPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+	if e, a := time.Duration(0), informer.processor.listeners[0].resyncPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+
+	// listener 2, resync every minute
+	listener2 := newTestListener("listener2", 1*time.Minute)
+	informer.AddEventHandlerWithResyncPeriod(listener2, listener2.resyncPeriod)
+	if e, a := 1*time.Minute, informer.resyncCheckPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+	if e, a := time.Duration(0), informer.processor.listeners[0].resyncPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+	if e, a := 1*time.Minute, informer.processor.listeners[1].resyncPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+
+	// listener 3, resync every 55 seconds
+	listener3 := newTestListener("listener3", 55*time.Second)
+	informer.AddEventHandlerWithResyncPeriod(listener3, listener3.resyncPeriod)
+	if e, a := 55*time.Second, informer.resyncCheckPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+	if e, a := 1*time.Minute, informer.processor.listeners[1].resyncPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+	if e, a := 55*time.Second, informer.processor.listeners[2].resyncPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+
+	// listener 4, resync every 5 seconds
+	listener4 := newTestListener("listener4", 5*time.Second)
+	handle, _ := informer.AddEventHandlerWithResyncPeriod(listener4, listener4.resyncPeriod)
+	if e, a := 5*time.Second, informer.resyncCheckPeriod; e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+	if e, a := 5*time.Second, handle.ResyncPeriod(); e != a {
+		t.Errorf("expected %d, got %d", e, a)
+	}
+}
+
+func TestRemoveEventHandler(t *testing.T) {
+	source := fcache.NewFakeControllerSource()
+	source.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}})
+
+	informer := NewSharedInformer(source, &v1.Pod{}, 0)
+
+	kept := newTestListener("kept", 0, "pod1")
+	if _, err := informer.AddEventHandlerWithResyncPeriod(kept, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	removed := newTestListener("removed", 0, "pod1")
+	handle, err := informer.AddEventHandlerWithResyncPeriod(removed, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go informer.Run(stop)
+
+	for _, listener := range []*testListener{kept, removed} {
+		if !listener.ok() {
+			t.Errorf("%s: expected %v, got %v", listener.name, listener.expectedItemNames, listener.received())
+		}
+		listener.reset()
+	}
+
+	if err := informer.RemoveEventHandler(handle); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := informer.RemoveEventHandler(handle); err == nil {
+		t.Errorf("expected an error removing a handler twice")
+	}
+	if n := len(informer.(*sharedIndexInformer).processor.listeners); n != 1 {
+		t.Errorf("expected 1 remaining listener, got %d", n)
+	}
+
+	kept.expectedItemNames = sets.NewString("pod2")
+	source.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2"}})
+	if !kept.ok() {
+		t.Errorf("%s: expected %v, got %v", kept.name, kept.expectedItemNames, kept.received())
+	}
+	if got := removed.received(); len(got) != 0 {
+		t.Errorf("removed handler should not receive events, got %v", got)
+	}
+}
+
+func TestSharedInformerTransform(t *testing.T) {
+	source := fcache.NewFakeControllerSource()
+	source.Add(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Annotations: map[string]string{"big": "value"}},
+		Spec:       v1.PodSpec{NodeName: "node1"},
+	})
+
+	informer := NewSharedIndexInformer(source, &v1.Pod{}, 0, Indexers{})
+	err := informer.SetTransform(func(obj interface{}) (interface{}, error) {
+		pod := obj.(*v1.Pod)
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            pod.Name,
+				Namespace:       pod.Namespace,
+				ResourceVersion: pod.ResourceVersion,
+			},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listener := newTestListener("listener", 0, "pod1")
+	informer.AddEventHandler(listener)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go informer.Run(stop)
+
+	if !listener.ok() {
+		t.Fatalf("%s: expected %v, got %v", listener.name, listener.expectedItemNames, listener.received())
+	}
+	items := informer.GetStore().List()
+	if len(items) != 1 {
+		t.Fatalf("expected one pod in the store, got %d", len(items))
+	}
+	pod := items[0].(*v1.Pod)
+	if len(pod.Annotations) != 0 || len(pod.Spec.NodeName) != 0 {
+		t.Errorf("expected the stored pod to be transformed, got %#v", pod)
+	}
+
+	if err := informer.SetTransform(nil); err == nil {
+		t.Errorf("expected an error setting the transform of a started informer")
+	}
+}
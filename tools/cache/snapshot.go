@@ -0,0 +1,128 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/pkg/api"
+)
+
+// SnapshotConfig configures a shared informer to save the contents of its cache to a
+// file and to restore them when it starts, so that it can serve reads and resume
+// watching without listing everything again.
+type SnapshotConfig struct {
+	// Path is the file the cache is saved to and restored from.
+	Path string
+	// Codec encodes and decodes the cached objects, for example
+	// api.Codecs.LegacyCodec(v1.SchemeGroupVersion).
+	Codec runtime.Codec
+	// Period is how often the cache is saved. If zero, the cache is only saved
+	// when the informer is stopped.
+	Period time.Duration
+}
+
+// cacheSnapshot is the format of the snapshot file.
+type cacheSnapshot struct {
+	// ResourceVersion is the resource version to resume watching from. The
+	// items are at least as fresh as this version.
+	ResourceVersion string `json:"resourceVersion"`
+	// Items are the cached objects, each encoded with the snapshot codec.
+	Items [][]byte `json:"items"`
+}
+
+// writeSnapshot encodes objs and atomically replaces the file at path with them.
+func writeSnapshot(path string, codec runtime.Codec, resourceVersion string, objs []interface{}) error {
+	snapshot := cacheSnapshot{
+		ResourceVersion: resourceVersion,
+		Items:           make([][]byte, 0, len(objs)),
+	}
+	for _, obj := range objs {
+		// Encoding may set the kind on the object, so never encode the cached copy.
+		copied, err := api.Scheme.DeepCopy(obj)
+		if err != nil {
+			return err
+		}
+		runtimeObj, ok := copied.(runtime.Object)
+		if !ok {
+			return fmt.Errorf("cannot encode %T, it is not a runtime.Object", obj)
+		}
+		buf := &bytes.Buffer{}
+		if err := codec.Encode(runtimeObj, buf); err != nil {
+			return err
+		}
+		snapshot.Items = append(snapshot.Items, buf.Bytes())
+	}
+	data, err := json.Marshal(&snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readSnapshot decodes the file at path into objects of the same type as objType.
+func readSnapshot(path string, codec runtime.Codec, objType runtime.Object) ([]interface{}, string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	snapshot := cacheSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, "", err
+	}
+	if len(snapshot.ResourceVersion) == 0 {
+		return nil, "", fmt.Errorf("snapshot %s has no resource version", path)
+	}
+	t := reflect.TypeOf(objType)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	objs := make([]interface{}, 0, len(snapshot.Items))
+	for _, item := range snapshot.Items {
+		into, ok := reflect.New(t).Interface().(runtime.Object)
+		if !ok {
+			return nil, "", fmt.Errorf("cannot decode into %v, it is not a runtime.Object", t)
+		}
+		obj, _, err := codec.Decode(item, nil, into)
+		if err != nil {
+			return nil, "", err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, snapshot.ResourceVersion, nil
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	apierrs "github.com/lavalamp/client-go-flat/apimachinery/pkg/api/errors"
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/watch"
+	"github.com/lavalamp/client-go-flat/pkg/api"
+	_ "github.com/lavalamp/client-go-flat/pkg/api/install"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+)
+
+func newSnapshotPod(name, resourceVersion string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", ResourceVersion: resourceVersion}}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pods")
+	codec := api.Codecs.LegacyCodec(v1.SchemeGroupVersion)
+
+	pod := newSnapshotPod("pod1", "5")
+	if err := writeSnapshot(path, codec, "10", []interface{}{pod}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pod.Kind) != 0 {
+		t.Errorf("expected the saved object to be left unchanged, got kind %q", pod.Kind)
+	}
+
+	objs, resourceVersion, err := readSnapshot(path, codec, &v1.Pod{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resourceVersion != "10" {
+		t.Errorf("expected resource version 10, got %q", resourceVersion)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("expected one object, got %d", len(objs))
+	}
+	restored, ok := objs[0].(*v1.Pod)
+	if !ok {
+		t.Fatalf("expected a *v1.Pod, got %T", objs[0])
+	}
+	if restored.Name != "pod1" || restored.Namespace != "ns" || restored.ResourceVersion != "5" {
+		t.Errorf("unexpected restored pod: %#v", restored.ObjectMeta)
+	}
+
+	if _, _, err := readSnapshot(filepath.Join(dir, "missing"), codec, &v1.Pod{}); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+// snapshotLW serves a single pod list and records the resource versions watched from.
+type snapshotLW struct {
+	lock       sync.Mutex
+	list       *v1.PodList
+	lists      int
+	watchRVs   []string
+	expireOnce bool
+}
+
+func (lw *snapshotLW) List(options metav1.ListOptions) (runtime.Object, error) {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+	lw.lists++
+	return lw.list, nil
+}
+
+func (lw *snapshotLW) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+	lw.watchRVs = append(lw.watchRVs, options.ResourceVersion)
+	if lw.expireOnce {
+		lw.expireOnce = false
+		return nil, apierrs.NewResourceExpired("too old resource version")
+	}
+	return watch.NewFake(), nil
+}
+
+func (lw *snapshotLW) calls() (int, []string) {
+	lw.lock.Lock()
+	defer lw.lock.Unlock()
+	return lw.lists, append([]string{}, lw.watchRVs...)
+}
+
+func storeNames(store Store) []string {
+	names := []string{}
+	for _, key := range store.ListKeys() {
+		names = append(names, key)
+	}
+	return names
+}
+
+func TestSharedInformerWarmStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := SnapshotConfig{
+		Path:  filepath.Join(dir, "pods"),
+		Codec: api.Codecs.LegacyCodec(v1.SchemeGroupVersion),
+	}
+	if err := writeSnapshot(config.Path, config.Codec, "10", []interface{}{newSnapshotPod("pod1", "5")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lw := &snapshotLW{list: &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "20"}}}
+	informer := NewSharedIndexInformer(lw, &v1.Pod{}, 0, Indexers{})
+	if err := informer.SetSnapshot(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := make(chan struct{})
+	go informer.Run(stop)
+
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, watchRVs := lw.calls()
+		return len(watchRVs) > 0 && len(informer.GetStore().ListKeys()) == 1, nil
+	})
+	if err != nil {
+		t.Fatalf("informer was not restored: %v", storeNames(informer.GetStore()))
+	}
+	if lists, watchRVs := lw.calls(); lists != 0 || !reflect.DeepEqual(watchRVs, []string{"10"}) {
+		t.Errorf("expected a watch from the snapshot without a list, got %d lists and watches %v", lists, watchRVs)
+	}
+	if _, exists, _ := informer.GetStore().GetByKey("ns/pod1"); !exists {
+		t.Errorf("expected the restored pod, got %v", storeNames(informer.GetStore()))
+	}
+
+	// stopping the informer saves the snapshot again
+	if err := os.Remove(config.Path); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, resourceVersion, err := readSnapshot(config.Path, config.Codec, &v1.Pod{})
+		return err == nil && resourceVersion == "10", nil
+	})
+	if err != nil {
+		t.Errorf("expected the snapshot to be saved on stop: %v", err)
+	}
+}
+
+func TestSharedInformerWarmStartExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := SnapshotConfig{
+		Path:   filepath.Join(dir, "pods"),
+		Codec:  api.Codecs.LegacyCodec(v1.SchemeGroupVersion),
+		Period: 10 * time.Millisecond,
+	}
+	if err := writeSnapshot(config.Path, config.Codec, "10", []interface{}{newSnapshotPod("pod1", "5")}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lw := &snapshotLW{
+		list:       &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "20"}, Items: []v1.Pod{*newSnapshotPod("pod2", "15")}},
+		expireOnce: true,
+	}
+	informer := NewSharedIndexInformer(lw, &v1.Pod{}, 0, Indexers{})
+	if err := informer.SetSnapshot(config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go informer.Run(stop)
+
+	// the expired resource version makes the informer relist and drop pod1
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		keys := informer.GetStore().ListKeys()
+		return len(keys) == 1 && keys[0] == "ns/pod2", nil
+	})
+	if err != nil {
+		t.Fatalf("expected only the listed pod, got %v", storeNames(informer.GetStore()))
+	}
+	if lists, watchRVs := lw.calls(); lists != 1 || watchRVs[0] != "10" {
+		t.Errorf("expected one list after the expired watch, got %d lists and watches %v", lists, watchRVs)
+	}
+
+	// the periodic snapshot picks up the relisted contents
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		objs, resourceVersion, err := readSnapshot(config.Path, config.Codec, &v1.Pod{})
+		return err == nil && resourceVersion == "20" && len(objs) == 1 && objs[0].(*v1.Pod).Name == "pod2", nil
+	})
+	if err != nil {
+		t.Errorf("expected a snapshot of the relisted pods: %v", err)
+	}
+}
@@ -174,9 +174,35 @@ func (config *DirectClientConfig) ClientConfig() (*restclient.Config, error) {
 		mergo.Merge(clientConfig, serverAuthPartialConfig)
 	}
 
+	if len(configClusterInfo.ProxyURL) > 0 {
+		proxyURL, err := parseProxyURL(configClusterInfo.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		clientConfig.ProxyURL = proxyURL
+	}
+
 	return clientConfig, nil
 }
 
+// parseProxyURL parses the proxy-url of a cluster, which must be an absolute URL with a
+// scheme supported by the transport.
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse proxy URL %q: %v", proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q for proxy URL %q, must be http, https, or socks5", u.Scheme, proxyURL)
+	}
+	if len(u.Host) == 0 {
+		return nil, fmt.Errorf("proxy URL %q has no host", proxyURL)
+	}
+	return u, nil
+}
+
 // clientauth.Info object contain both user identification and server identification.  We want different precedence orders for
 // both, so we have to split the objects and merge them separately
 // we want this order of precedence for the server identification
@@ -215,6 +241,7 @@ func (config *DirectClientConfig) getUserIdentificationPartialConfig(configAuthI
 			return nil, err
 		}
 		mergedConfig.BearerToken = string(tokenBytes)
+		mergedConfig.BearerTokenFile = configAuthInfo.TokenFile
 	}
 	if len(configAuthInfo.Impersonate) > 0 {
 		mergedConfig.Impersonate = restclient.ImpersonationConfig{UserName: configAuthInfo.Impersonate}
@@ -233,6 +260,9 @@ func (config *DirectClientConfig) getUserIdentificationPartialConfig(configAuthI
 		mergedConfig.AuthProvider = configAuthInfo.AuthProvider
 		mergedConfig.AuthConfigPersister = persistAuthConfig
 	}
+	if configAuthInfo.Exec != nil {
+		mergedConfig.ExecProvider = configAuthInfo.Exec
+	}
 
 	// if there still isn't enough information to authenticate the user, try prompting
 	if !canIdentifyUser(*mergedConfig) && (fallbackReader != nil) {
@@ -283,7 +313,9 @@ func canIdentifyUser(config restclient.Config) bool {
 	return len(config.Username) > 0 ||
 		(len(config.CertFile) > 0 || len(config.CertData) > 0) ||
 		len(config.BearerToken) > 0 ||
-		config.AuthProvider != nil
+		len(config.BearerTokenFile) > 0 ||
+		config.AuthProvider != nil ||
+		config.ExecProvider != nil
 }
 
 // Namespace implements ClientConfig
@@ -223,6 +223,65 @@ func TestBasicTokenFile(t *testing.T) {
 	}
 
 	matchStringArg(token, clientConfig.BearerToken, t)
+	matchStringArg(f.Name(), clientConfig.BearerTokenFile, t)
+}
+
+func TestExecProvider(t *testing.T) {
+	exec := &clientcmdapi.ExecConfig{
+		Command:    "/usr/bin/example-credential-plugin",
+		Args:       []string{"token"},
+		APIVersion: "client.authentication.k8s.io/v1alpha1",
+	}
+	config := clientcmdapi.NewConfig()
+	config.Clusters["clean"] = &clientcmdapi.Cluster{
+		Server: "https://localhost:8443",
+	}
+	config.AuthInfos["clean"] = &clientcmdapi.AuthInfo{
+		Exec: exec,
+	}
+	config.Contexts["clean"] = &clientcmdapi.Context{
+		Cluster:  "clean",
+		AuthInfo: "clean",
+	}
+	config.CurrentContext = "clean"
+
+	clientBuilder := NewNonInteractiveClientConfig(*config, "clean", &ConfigOverrides{}, nil)
+
+	clientConfig, err := clientBuilder.ClientConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(clientConfig.ExecProvider, exec) {
+		t.Errorf("Expected exec provider %#v, got %#v", exec, clientConfig.ExecProvider)
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["clean"] = &clientcmdapi.Cluster{
+		Server:   "https://localhost:8443",
+		ProxyURL: "socks5://bastion.example.com:1080",
+	}
+	config.AuthInfos["clean"] = &clientcmdapi.AuthInfo{}
+	config.Contexts["clean"] = &clientcmdapi.Context{
+		Cluster:  "clean",
+		AuthInfo: "clean",
+	}
+	config.CurrentContext = "clean"
+
+	clientBuilder := NewNonInteractiveClientConfig(*config, "clean", &ConfigOverrides{}, nil)
+
+	clientConfig, err := clientBuilder.ClientConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clientConfig.ProxyURL == nil {
+		t.Fatalf("Expected a proxy to be configured")
+	}
+	if proxyURL := clientConfig.ProxyURL.String(); proxyURL != "socks5://bastion.example.com:1080" {
+		t.Errorf("Expected socks5://bastion.example.com:1080, got %v", proxyURL)
+	}
 }
 
 func TestPrecedenceTokenFile(t *testing.T) {
@@ -210,6 +210,20 @@ func TestValidateMissingCAFileClusterInfo(t *testing.T) {
 	test.testCluster("missing ca", t)
 	test.testConfig(t)
 }
+func TestValidateInvalidProxyURLClusterInfo(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["bad proxy"] = &clientcmdapi.Cluster{
+		Server:   "anything",
+		ProxyURL: "ftp://proxy.example.com",
+	}
+	test := configValidationTest{
+		config:                 config,
+		expectedErrorSubstring: []string{"invalid proxy-url"},
+	}
+
+	test.testCluster("bad proxy", t)
+	test.testConfig(t)
+}
 func TestValidateCleanClusterInfo(t *testing.T) {
 	config := clientcmdapi.NewConfig()
 	config.Clusters["clean"] = &clientcmdapi.Cluster{
@@ -326,6 +340,43 @@ func TestValidateMultipleMethodsAuthInfo(t *testing.T) {
 	test.testConfig(t)
 }
 
+func TestValidateExecAuthInfo(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.AuthInfos["clean"] = &clientcmdapi.AuthInfo{
+		Exec: &clientcmdapi.ExecConfig{
+			Command:    "/bin/example",
+			APIVersion: "client.authentication.k8s.io/v1alpha1",
+			Env:        []clientcmdapi.ExecEnvVar{{Name: "foo", Value: "bar"}},
+		},
+	}
+	test := configValidationTest{
+		config: config,
+	}
+
+	test.testAuthInfo("clean", t)
+	test.testConfig(t)
+
+	config = clientcmdapi.NewConfig()
+	config.AuthInfos["error"] = &clientcmdapi.AuthInfo{
+		AuthProvider: &clientcmdapi.AuthProviderConfig{Name: "gcp"},
+		Exec: &clientcmdapi.ExecConfig{
+			Env: []clientcmdapi.ExecEnvVar{{Value: "bar"}},
+		},
+	}
+	test = configValidationTest{
+		config: config,
+		expectedErrorSubstring: []string{
+			"authProvider cannot be provided in combination with an exec plugin",
+			"command must be specified",
+			"apiVersion must be specified",
+			"env variable name must be specified",
+		},
+	}
+
+	test.testAuthInfo("error", t)
+	test.testConfig(t)
+}
+
 type configValidationTest struct {
 	config                 *clientcmdapi.Config
 	expectedErrorSubstring []string
@@ -0,0 +1,291 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller provides the loop shared by most controllers: informer
+// event handlers enqueue namespace/name keys into a rate limited workqueue and
+// a pool of workers hands each key to a reconcile function, retrying failures
+// with backoff until a retry limit is reached.
+package controller // import "github.com/lavalamp/client-go-flat/tools/controller"
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/api/meta"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime/schema"
+	utilruntime "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/runtime"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/tools/cache"
+	"github.com/lavalamp/client-go-flat/util/workqueue"
+)
+
+// DefaultMaxRetries is the number of times a key is retried before it is
+// dropped out of the queue when Config.MaxRetries is not set.
+const DefaultMaxRetries = 15
+
+// ReconcileFunc brings the object identified by key, a namespace/name key as
+// produced by cache.MetaNamespaceKeyFunc, to its desired state.  A non-nil
+// error requeues the key with rate limiting.  Otherwise a positive
+// requeueAfter requeues the key once that duration has passed.
+type ReconcileFunc func(key string) (requeueAfter time.Duration, err error)
+
+// OwnerFunc maps an object of a watched type to the keys of the objects that
+// should be reconciled when it changes.
+type OwnerFunc func(obj interface{}) []string
+
+// Config describes a Controller.
+type Config struct {
+	// Name identifies the controller in logs and metrics.  It is also the
+	// name of the controller's workqueue.
+	Name string
+
+	// Reconcile is called by the workers for every key taken from the queue.
+	// A panic in Reconcile is logged and retried like an error.
+	Reconcile ReconcileFunc
+
+	// MaxRetries is the number of times a failing key is requeued before it
+	// is dropped.  DefaultMaxRetries is used when it is zero and a negative
+	// value retries forever.
+	MaxRetries int
+
+	// RateLimiter decides how long failing keys wait before they are retried.
+	// workqueue.DefaultControllerRateLimiter() is used when it is nil.
+	RateLimiter workqueue.RateLimiter
+}
+
+// Controller feeds keys from the informers it watches to a pool of workers
+// calling a ReconcileFunc.
+type Controller struct {
+	name       string
+	reconcile  ReconcileFunc
+	maxRetries int
+	queue      workqueue.RateLimitingInterface
+	metrics    *controllerMetrics
+
+	lock      sync.Mutex
+	hasSynced []cache.InformerSynced
+	started   bool
+}
+
+// New creates a Controller from the given Config.
+func New(config Config) (*Controller, error) {
+	if len(config.Name) == 0 {
+		return nil, fmt.Errorf("controller name must not be empty")
+	}
+	if config.Reconcile == nil {
+		return nil, fmt.Errorf("controller %q has no reconcile function", config.Name)
+	}
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	rateLimiter := config.RateLimiter
+	if rateLimiter == nil {
+		rateLimiter = workqueue.DefaultControllerRateLimiter()
+	}
+	return &Controller{
+		name:       config.Name,
+		reconcile:  config.Reconcile,
+		maxRetries: maxRetries,
+		queue:      workqueue.NewNamedRateLimitingQueue(rateLimiter, config.Name),
+		metrics:    newControllerMetrics(config.Name),
+	}, nil
+}
+
+// Watch enqueues the key of every object added, updated or deleted in the
+// informer.  It must be called before Run.
+func (c *Controller) Watch(informer cache.SharedInformer) error {
+	return c.watch(informer, func(obj interface{}) []string {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			utilruntime.HandleError(fmt.Errorf("%s: couldn't get key for object %+v: %v", c.name, obj, err))
+			return nil
+		}
+		return []string{key}
+	})
+}
+
+// WatchOwned enqueues the keys that owner returns for every object added,
+// updated or deleted in the informer.  For updates the owners of both the old
+// and the new object are enqueued, so that a change of owner reaches both.  It
+// must be called before Run.
+func (c *Controller) WatchOwned(informer cache.SharedInformer, owner OwnerFunc) error {
+	return c.watch(informer, func(obj interface{}) []string {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		return owner(obj)
+	})
+}
+
+func (c *Controller) watch(informer cache.SharedInformer, keys func(obj interface{}) []string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.started {
+		return fmt.Errorf("controller %q has already started", c.name)
+	}
+	enqueue := func(obj interface{}) {
+		for _, key := range keys(obj) {
+			c.queue.Add(key)
+		}
+	}
+	err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			enqueue(oldObj)
+			enqueue(newObj)
+		},
+		DeleteFunc: enqueue,
+	})
+	if err != nil {
+		return err
+	}
+	c.hasSynced = append(c.hasSynced, informer.HasSynced)
+	return nil
+}
+
+// Enqueue adds key to the queue.
+func (c *Controller) Enqueue(key string) {
+	c.queue.Add(key)
+}
+
+// ControllerOwnerKeys returns an OwnerFunc mapping an object to the key of its
+// controller, as recorded in its owner references, when that controller is of
+// the given group and kind.
+func ControllerOwnerKeys(ownerKind schema.GroupKind) OwnerFunc {
+	return func(obj interface{}) []string {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil
+		}
+		for _, ref := range accessor.GetOwnerReferences() {
+			if ref.Controller == nil || !*ref.Controller {
+				continue
+			}
+			gv, err := schema.ParseGroupVersion(ref.APIVersion)
+			if err != nil || gv.WithKind(ref.Kind).GroupKind() != ownerKind {
+				return nil
+			}
+			if len(accessor.GetNamespace()) == 0 {
+				return []string{ref.Name}
+			}
+			return []string{accessor.GetNamespace() + "/" + ref.Name}
+		}
+		return nil
+	}
+}
+
+// Run waits for the watched informers to sync and then starts workers
+// goroutines processing keys.  It blocks until stopCh is closed, after which it
+// shuts down the queue and waits for the reconciles in progress to finish.  Keys
+// still in the queue are abandoned.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	c.lock.Lock()
+	if c.started {
+		c.lock.Unlock()
+		utilruntime.HandleError(fmt.Errorf("controller %q has already started", c.name))
+		return
+	}
+	c.started = true
+	hasSynced := c.hasSynced
+	c.lock.Unlock()
+
+	glog.Infof("Starting %s controller", c.name)
+	defer glog.Infof("Shutting down %s controller", c.name)
+
+	if !cache.WaitForCacheSync(stopCh, hasSynced...) {
+		utilruntime.HandleError(fmt.Errorf("%s: timed out waiting for caches to sync", c.name))
+		c.queue.ShutDown()
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(c.worker, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+	c.queue.ShutDown()
+	wg.Wait()
+}
+
+// worker processes keys until the queue is shut down.
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	item, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(item)
+	// once shutting down, only the reconciles already in progress are finished
+	if c.queue.ShuttingDown() {
+		return false
+	}
+
+	key := item.(string)
+	requeueAfter, err := c.reconcileHandler(key)
+
+	if err == nil {
+		c.queue.Forget(item)
+		if requeueAfter > 0 {
+			c.queue.AddAfter(item, requeueAfter)
+		}
+		return true
+	}
+
+	if c.maxRetries < 0 || c.queue.NumRequeues(item) < c.maxRetries {
+		glog.V(2).Infof("%s: error reconciling %q, retrying: %v", c.name, key, err)
+		c.queue.AddRateLimited(item)
+		return true
+	}
+
+	c.metrics.drops.Inc()
+	c.queue.Forget(item)
+	utilruntime.HandleError(fmt.Errorf("%s: dropping %q out of the queue after %d retries: %v", c.name, key, c.maxRetries, err))
+	return true
+}
+
+// reconcileHandler calls the reconcile function for key and records its
+// metrics.  A panic is logged and turned into an error, so that the key is
+// retried like any other failure instead of killing the worker.
+func (c *Controller) reconcileHandler(key string) (requeueAfter time.Duration, err error) {
+	c.metrics.activeWorkers.Inc()
+	defer c.metrics.activeWorkers.Dec()
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			for _, fn := range utilruntime.PanicHandlers {
+				fn(r)
+			}
+			requeueAfter, err = 0, fmt.Errorf("panic: %v", r)
+		}
+		c.metrics.reconcile(start, err)
+	}()
+	return c.reconcile(key)
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime/schema"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/informers"
+	"github.com/lavalamp/client-go-flat/kubernetes/fake"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	"github.com/lavalamp/client-go-flat/util/workqueue"
+)
+
+type keyRecorder struct {
+	lock sync.Mutex
+	keys []string
+}
+
+func (r *keyRecorder) record(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.keys = append(r.keys, key)
+}
+
+func (r *keyRecorder) get() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.keys...)
+}
+
+func TestControllerWatchAndWatchOwned(t *testing.T) {
+	isController := true
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod1", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "extensions/v1beta1", Kind: "ReplicaSet", Name: "rs1", Controller: &isController},
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod2", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "v1", Kind: "ReplicationController", Name: "rc1", Controller: &isController},
+		}}},
+	}
+	client := fake.NewSimpleClientset(pods[0], pods[1])
+	factory := informers.NewSharedInformerFactory(client, 0)
+
+	recorder := &keyRecorder{}
+	c, err := New(Config{
+		Name: "test-watch",
+		Reconcile: func(key string) (time.Duration, error) {
+			recorder.record(key)
+			return 0, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	podInformer := factory.Core().V1().Pods().Informer()
+	if err := c.Watch(podInformer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.WatchOwned(podInformer, ControllerOwnerKeys(schema.GroupKind{Group: "extensions", Kind: "ReplicaSet"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	go c.Run(2, stopCh)
+
+	expected := []string{"ns/pod1", "ns/pod2", "ns/rs1"}
+	var got []string
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		got = recorder.get()
+		sort.Strings(got)
+		return reflect.DeepEqual(got, expected), nil
+	})
+	if err != nil {
+		t.Errorf("expected reconciles for %v, got %v", expected, got)
+	}
+
+	if err := c.Watch(podInformer); err == nil {
+		t.Errorf("expected an error watching after the controller started")
+	}
+}
+
+type countingMetric struct {
+	lock  sync.Mutex
+	count int
+}
+
+func (m *countingMetric) Inc() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.count++
+}
+
+func (m *countingMetric) Dec() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.count--
+}
+
+func (m *countingMetric) Observe(float64) {}
+
+func (m *countingMetric) get() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.count
+}
+
+type testMetricsProvider struct {
+	reconciles, reconcileErrors, drops, activeWorkers countingMetric
+}
+
+func (p *testMetricsProvider) NewReconcilesMetric(string) CounterMetric { return &p.reconciles }
+func (p *testMetricsProvider) NewReconcileErrorsMetric(string) CounterMetric {
+	return &p.reconcileErrors
+}
+func (p *testMetricsProvider) NewReconcileDurationMetric(string) SummaryMetric {
+	return noopMetric{}
+}
+func (p *testMetricsProvider) NewDropsMetric(string) CounterMetric       { return &p.drops }
+func (p *testMetricsProvider) NewActiveWorkersMetric(string) GaugeMetric { return &p.activeWorkers }
+
+func TestControllerRetriesAndDrops(t *testing.T) {
+	metrics := &testMetricsProvider{}
+	SetMetricsProvider(metrics)
+
+	recorder := &keyRecorder{}
+	c, err := New(Config{
+		Name:        "test-retries",
+		MaxRetries:  2,
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond),
+		Reconcile: func(key string) (time.Duration, error) {
+			recorder.record(key)
+			return 0, errors.New("failed")
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(1, stopCh)
+	c.Enqueue("ns/foo")
+
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return metrics.drops.get() == 1, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the key to be dropped")
+	}
+	// the initial attempt plus MaxRetries retries
+	if got := recorder.get(); len(got) != 3 {
+		t.Errorf("expected 3 reconciles, got %v", got)
+	}
+	if metrics.reconciles.get() != 3 || metrics.reconcileErrors.get() != 3 {
+		t.Errorf("expected 3 failed reconciles, got %d reconciles and %d errors", metrics.reconciles.get(), metrics.reconcileErrors.get())
+	}
+	if metrics.activeWorkers.get() != 0 {
+		t.Errorf("expected no active workers, got %d", metrics.activeWorkers.get())
+	}
+	if c.queue.NumRequeues("ns/foo") != 0 {
+		t.Errorf("expected a dropped key to be forgotten")
+	}
+}
+
+func TestControllerRetriesPanics(t *testing.T) {
+	recorder := &keyRecorder{}
+	c, err := New(Config{
+		Name:        "test-panics",
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond),
+		Reconcile: func(key string) (time.Duration, error) {
+			recorder.record(key)
+			if len(recorder.get()) == 1 {
+				panic("reconcile bug")
+			}
+			return 0, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the metrics provider can only be set once per process
+	metrics := &testMetricsProvider{}
+	c.metrics = &controllerMetrics{
+		reconciles:        &metrics.reconciles,
+		reconcileErrors:   &metrics.reconcileErrors,
+		reconcileDuration: noopMetric{},
+		drops:             &metrics.drops,
+		activeWorkers:     &metrics.activeWorkers,
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(1, stopCh)
+	c.Enqueue("ns/foo")
+
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return metrics.reconciles.get() == 2, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the key to be retried after the panic, got %v", recorder.get())
+	}
+	if metrics.reconcileErrors.get() != 1 {
+		t.Errorf("expected the panic to be counted as an error, got %d errors", metrics.reconcileErrors.get())
+	}
+	if metrics.activeWorkers.get() != 0 {
+		t.Errorf("expected no active workers, got %d", metrics.activeWorkers.get())
+	}
+}
+
+func TestControllerRequeueAfter(t *testing.T) {
+	recorder := &keyRecorder{}
+	c, err := New(Config{
+		Name: "test-requeue",
+		Reconcile: func(key string) (time.Duration, error) {
+			recorder.record(key)
+			if len(recorder.get()) == 1 {
+				return 10 * time.Millisecond, nil
+			}
+			return 0, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(1, stopCh)
+	c.Enqueue("ns/foo")
+
+	err = wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return len(recorder.get()) == 2, nil
+	})
+	if err != nil {
+		t.Errorf("expected the key to be reconciled again, got %v", recorder.get())
+	}
+}
+
+func TestControllerGracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c, err := New(Config{
+		Name: "test-shutdown",
+		Reconcile: func(key string) (time.Duration, error) {
+			close(started)
+			<-release
+			return 0, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		c.Run(1, stopCh)
+		close(done)
+	}()
+	c.Enqueue("ns/foo")
+	<-started
+	// queued behind the reconcile in progress, and abandoned on shutdown
+	c.Enqueue("ns/bar")
+
+	close(stopCh)
+	select {
+	case <-done:
+		t.Fatalf("Run returned while a reconcile was in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("timed out waiting for Run to return")
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	reconcile := func(string) (time.Duration, error) { return 0, nil }
+	if _, err := New(Config{Reconcile: reconcile}); err == nil {
+		t.Errorf("expected an error for a missing name")
+	}
+	if _, err := New(Config{Name: "foo"}); err == nil {
+		t.Errorf("expected an error for a missing reconcile function")
+	}
+}
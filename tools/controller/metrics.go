@@ -0,0 +1,114 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// This file provides abstractions for setting the provider (e.g., prometheus)
+// of metrics.
+
+// GaugeMetric represents a single numerical value that can arbitrarily go up
+// and down.
+type GaugeMetric interface {
+	Inc()
+	Dec()
+}
+
+// CounterMetric represents a single numerical value that only ever
+// goes up.
+type CounterMetric interface {
+	Inc()
+}
+
+// SummaryMetric captures individual observations.
+type SummaryMetric interface {
+	Observe(float64)
+}
+
+type noopMetric struct{}
+
+func (noopMetric) Inc()            {}
+func (noopMetric) Dec()            {}
+func (noopMetric) Observe(float64) {}
+
+type controllerMetrics struct {
+	// number of reconciles performed
+	reconciles CounterMetric
+	// number of reconciles that returned an error
+	reconcileErrors CounterMetric
+	// how long a reconcile takes, in seconds
+	reconcileDuration SummaryMetric
+	// number of keys dropped after exhausting their retries
+	drops CounterMetric
+	// number of workers currently running a reconcile
+	activeWorkers GaugeMetric
+}
+
+func (m *controllerMetrics) reconcile(start time.Time, err error) {
+	m.reconciles.Inc()
+	m.reconcileDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.reconcileErrors.Inc()
+	}
+}
+
+// MetricsProvider generates various metrics used by the controller.
+type MetricsProvider interface {
+	NewReconcilesMetric(name string) CounterMetric
+	NewReconcileErrorsMetric(name string) CounterMetric
+	NewReconcileDurationMetric(name string) SummaryMetric
+	NewDropsMetric(name string) CounterMetric
+	NewActiveWorkersMetric(name string) GaugeMetric
+}
+
+type noopMetricsProvider struct{}
+
+func (noopMetricsProvider) NewReconcilesMetric(name string) CounterMetric        { return noopMetric{} }
+func (noopMetricsProvider) NewReconcileErrorsMetric(name string) CounterMetric   { return noopMetric{} }
+func (noopMetricsProvider) NewReconcileDurationMetric(name string) SummaryMetric { return noopMetric{} }
+func (noopMetricsProvider) NewDropsMetric(name string) CounterMetric             { return noopMetric{} }
+func (noopMetricsProvider) NewActiveWorkersMetric(name string) GaugeMetric       { return noopMetric{} }
+
+var metricsFactory = struct {
+	metricsProvider MetricsProvider
+	setProviders    sync.Once
+}{
+	metricsProvider: noopMetricsProvider{},
+}
+
+func newControllerMetrics(name string) *controllerMetrics {
+	p := metricsFactory.metricsProvider
+	return &controllerMetrics{
+		reconciles:        p.NewReconcilesMetric(name),
+		reconcileErrors:   p.NewReconcileErrorsMetric(name),
+		reconcileDuration: p.NewReconcileDurationMetric(name),
+		drops:             p.NewDropsMetric(name),
+		activeWorkers:     p.NewActiveWorkersMetric(name),
+	}
+}
+
+// SetMetricsProvider sets the metrics provider of the metricsFactory.  Only
+// the first call has an effect, and it must happen before controllers are
+// created.
+func SetMetricsProvider(metricsProvider MetricsProvider) {
+	metricsFactory.setProviders.Do(func() {
+		metricsFactory.metricsProvider = metricsProvider
+	})
+}
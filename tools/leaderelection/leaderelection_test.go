@@ -0,0 +1,261 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/kubernetes/fake"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+	rl "github.com/lavalamp/client-go-flat/tools/leaderelection/resourcelock"
+	"github.com/lavalamp/client-go-flat/tools/record"
+)
+
+func annotatedMeta(t *testing.T, holder string) metav1.ObjectMeta {
+	record := rl.LeaderElectionRecord{HolderIdentity: holder, LeaseDurationSeconds: 10, LeaderTransitions: 1}
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return metav1.ObjectMeta{
+		Namespace:   "foo",
+		Name:        "bar",
+		Annotations: map[string]string{rl.LeaderElectionRecordAnnotationKey: string(recordBytes)},
+	}
+}
+
+func TestTryAcquireOrRenew(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		lockType     string
+		objects      func(t *testing.T) []runtime.Object
+		observedTime time.Time
+
+		expectSuccess     bool
+		expectLeader      string
+		expectTransitions int
+	}{
+		{
+			name:          "create endpoints lock",
+			lockType:      rl.EndpointsResourceLock,
+			objects:       func(t *testing.T) []runtime.Object { return nil },
+			expectSuccess: true,
+			expectLeader:  "baz",
+		},
+		{
+			name:     "acquire unheld endpoints lock",
+			lockType: rl.EndpointsResourceLock,
+			objects: func(t *testing.T) []runtime.Object {
+				return []runtime.Object{&v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar"}}}
+			},
+			expectSuccess:     true,
+			expectLeader:      "baz",
+			expectTransitions: 1,
+		},
+		{
+			name:     "acquire expired configmap lock",
+			lockType: rl.ConfigMapsResourceLock,
+			objects: func(t *testing.T) []runtime.Object {
+				return []runtime.Object{&v1.ConfigMap{ObjectMeta: annotatedMeta(t, "bing")}}
+			},
+			observedTime:      past,
+			expectSuccess:     true,
+			expectLeader:      "baz",
+			expectTransitions: 2,
+		},
+		{
+			name:     "configmap lock held by another",
+			lockType: rl.ConfigMapsResourceLock,
+			objects: func(t *testing.T) []runtime.Object {
+				return []runtime.Object{&v1.ConfigMap{ObjectMeta: annotatedMeta(t, "bing")}}
+			},
+			observedTime:      future,
+			expectLeader:      "bing",
+			expectTransitions: 1,
+		},
+		{
+			name:     "renew own endpoints lock",
+			lockType: rl.EndpointsResourceLock,
+			objects: func(t *testing.T) []runtime.Object {
+				return []runtime.Object{&v1.Endpoints{ObjectMeta: annotatedMeta(t, "baz")}}
+			},
+			observedTime:      future,
+			expectSuccess:     true,
+			expectLeader:      "baz",
+			expectTransitions: 1,
+		},
+	}
+
+	for _, test := range tests {
+		client := fake.NewSimpleClientset(test.objects(t)...)
+		lock, err := rl.New(test.lockType, "foo", "bar", client.CoreV1(), rl.ResourceLockConfig{Identity: "baz"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		le := &LeaderElector{
+			config: LeaderElectionConfig{
+				Lock:          lock,
+				LeaseDuration: 10 * time.Second,
+				Callbacks: LeaderCallbacks{
+					OnNewLeader: func(string) {},
+				},
+			},
+		}
+		// seed the observed record so the lease expiry is measured from observedTime
+		if existing, err := lock.Get(); err == nil {
+			le.observedRecord = *existing
+			le.observedTime = test.observedTime
+		}
+
+		if got := le.tryAcquireOrRenew(); got != test.expectSuccess {
+			t.Errorf("%s: expected success %v, got %v", test.name, test.expectSuccess, got)
+		}
+		if got := le.GetLeader(); got != test.expectLeader {
+			t.Errorf("%s: expected leader %q, got %q", test.name, test.expectLeader, got)
+		}
+		if le.IsLeader() != (test.expectLeader == "baz") {
+			t.Errorf("%s: unexpected IsLeader result", test.name)
+		}
+		stored, err := lock.Get()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if stored.HolderIdentity != test.expectLeader || stored.LeaderTransitions != test.expectTransitions {
+			t.Errorf("%s: unexpected stored record %#v", test.name, stored)
+		}
+	}
+}
+
+func TestNewLeaderElectorValidation(t *testing.T) {
+	lock, err := rl.New(rl.EndpointsResourceLock, "foo", "bar", fake.NewSimpleClientset().CoreV1(), rl.ResourceLockConfig{Identity: "baz"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	valid := LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+		Callbacks: LeaderCallbacks{
+			OnStartedLeading: func(<-chan struct{}) {},
+			OnStoppedLeading: func() {},
+		},
+	}
+	if _, err := NewLeaderElector(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := []func(*LeaderElectionConfig){
+		func(c *LeaderElectionConfig) { c.RenewDeadline = c.LeaseDuration },
+		func(c *LeaderElectionConfig) { c.RetryPeriod = c.RenewDeadline },
+		func(c *LeaderElectionConfig) { c.Lock = nil },
+		func(c *LeaderElectionConfig) { c.Callbacks.OnStartedLeading = nil },
+		func(c *LeaderElectionConfig) { c.Callbacks.OnStoppedLeading = nil },
+	}
+	for i, mutate := range invalid {
+		config := valid
+		mutate(&config)
+		if _, err := NewLeaderElector(config); err == nil {
+			t.Errorf("%d: expected a validation error", i)
+		}
+	}
+}
+
+func TestRunLosesLeadership(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+	lock, err := rl.New(rl.ConfigMapsResourceLock, "foo", "bar", client.CoreV1(), rl.ResourceLockConfig{Identity: "baz", EventRecorder: recorder})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	started := make(chan (<-chan struct{}), 1)
+	newLeader := make(chan string, 2)
+	le, err := NewLeaderElector(LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: 300 * time.Millisecond,
+		RenewDeadline: 200 * time.Millisecond,
+		RetryPeriod:   50 * time.Millisecond,
+		Callbacks: LeaderCallbacks{
+			OnStartedLeading: func(stop <-chan struct{}) { started <- stop },
+			OnStoppedLeading: func() {},
+			OnNewLeader:      func(identity string) { newLeader <- identity },
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		le.Run()
+		close(done)
+	}()
+
+	var stop <-chan struct{}
+	select {
+	case stop = <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for leadership")
+	}
+	if identity := <-newLeader; identity != "baz" {
+		t.Errorf("expected new leader baz, got %q", identity)
+	}
+
+	// another candidate takes over the lock, so renewals must fail
+	cm, err := client.CoreV1().ConfigMaps("foo").Get("bar", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recordBytes, _ := json.Marshal(rl.LeaderElectionRecord{HolderIdentity: "bing", LeaseDurationSeconds: 3600, RenewTime: metav1.Now()})
+	cm.Annotations[rl.LeaderElectionRecordAnnotationKey] = string(recordBytes)
+	if _, err := client.CoreV1().ConfigMaps("foo").Update(cm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for Run to return")
+	}
+	select {
+	case <-stop:
+	default:
+		t.Errorf("expected the OnStartedLeading stop channel to be closed")
+	}
+	if le.IsLeader() {
+		t.Errorf("expected to no longer be the leader")
+	}
+	if identity := <-newLeader; identity != "bing" {
+		t.Errorf("expected new leader bing, got %q", identity)
+	}
+
+	for _, expected := range []string{"baz became leader", "baz stopped leading"} {
+		event := <-recorder.Events
+		if !strings.Contains(event, expected) {
+			t.Errorf("expected event %q, got %q", expected, event)
+		}
+	}
+}
@@ -24,7 +24,11 @@ import (
 	"time"
 )
 
-var registerMetrics sync.Once
+var (
+	registerMetrics            sync.Once
+	registerRetryMetrics       sync.Once
+	registerRateLimiterMetrics sync.Once
+)
 
 // LatencyMetric observes client latency partitioned by verb and url.
 type LatencyMetric interface {
@@ -36,11 +40,27 @@ type ResultMetric interface {
 	Increment(code string, method string, host string)
 }
 
+// RetryMetric counts request retries partitioned by the response code, or
+// "<error>", of the attempt being retried, method and host.
+type RetryMetric interface {
+	IncrementRetry(code string, method string, host string)
+}
+
+// RateLimiterMetric observes how long requests waited for the client side
+// rate limiter, partitioned by rate limit class.
+type RateLimiterMetric interface {
+	ObserveWait(class string, wait time.Duration)
+}
+
 var (
 	// RequestLatency is the latency metric that rest clients will update.
 	RequestLatency LatencyMetric = noopLatency{}
 	// RequestResult is the result metric that rest clients will update.
 	RequestResult ResultMetric = noopResult{}
+	// RequestRetry is the retry metric that rest clients will update.
+	RequestRetry RetryMetric = noopRetry{}
+	// RateLimiterWait is the rate limiter wait metric that rest clients will update.
+	RateLimiterWait RateLimiterMetric = noopRateLimiter{}
 )
 
 // Register registers metrics for the rest client to use. This can
@@ -52,6 +72,22 @@ func Register(lm LatencyMetric, rm ResultMetric) {
 	})
 }
 
+// RegisterRetry registers the retry metric for the rest client to use. This
+// can only be called once.
+func RegisterRetry(rm RetryMetric) {
+	registerRetryMetrics.Do(func() {
+		RequestRetry = rm
+	})
+}
+
+// RegisterRateLimiter registers the rate limiter wait metric for the rest
+// client to use. This can only be called once.
+func RegisterRateLimiter(rm RateLimiterMetric) {
+	registerRateLimiterMetrics.Do(func() {
+		RateLimiterWait = rm
+	})
+}
+
 type noopLatency struct{}
 
 func (noopLatency) Observe(string, url.URL, time.Duration) {}
@@ -59,3 +95,11 @@ func (noopLatency) Observe(string, url.URL, time.Duration) {}
 type noopResult struct{}
 
 func (noopResult) Increment(string, string, string) {}
+
+type noopRetry struct{}
+
+func (noopRetry) IncrementRetry(string, string, string) {}
+
+type noopRateLimiter struct{}
+
+func (noopRateLimiter) ObserveWait(string, time.Duration) {}
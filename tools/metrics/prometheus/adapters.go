@@ -0,0 +1,221 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/tools/cache"
+	"github.com/lavalamp/client-go-flat/tools/metrics"
+	"github.com/lavalamp/client-go-flat/util/workqueue"
+)
+
+// Register installs metrics providers backed by r in the workqueue, rest
+// client and reflector packages.  Those packages only accept the first
+// provider they are given, so Register should be called once, early in main,
+// before any queue, client or informer is created.
+func Register(r *Registry) {
+	workqueue.SetProvider(NewWorkqueueMetricsProvider(r))
+	metrics.Register(NewRequestLatencyMetric(r), NewRequestResultMetric(r))
+	metrics.RegisterRetry(NewRequestRetryMetric(r))
+	metrics.RegisterRateLimiter(NewRateLimiterWaitMetric(r))
+	cache.SetReflectorMetricsProvider(NewReflectorMetricsProvider(r))
+}
+
+type workqueueMetricsProvider struct {
+	depth                   *GaugeVec
+	adds                    *CounterVec
+	latency                 *SummaryVec
+	workDuration            *SummaryVec
+	retries                 *CounterVec
+	unfinishedWork          *GaugeVec
+	longestRunningProcessor *GaugeVec
+}
+
+// NewWorkqueueMetricsProvider returns a workqueue.MetricsProvider recording
+// into r, with the queue name as the "name" label.
+func NewWorkqueueMetricsProvider(r *Registry) workqueue.MetricsProvider {
+	return &workqueueMetricsProvider{
+		depth:        r.NewGaugeVec("workqueue_depth", "Current depth of workqueue.", "name"),
+		adds:         r.NewCounterVec("workqueue_adds_total", "Total number of adds handled by workqueue.", "name"),
+		latency:      r.NewSummaryVec("workqueue_queue_latency_microseconds", "How long an item stays in workqueue before being requested.", "name"),
+		workDuration: r.NewSummaryVec("workqueue_work_duration_microseconds", "How long processing an item from workqueue takes.", "name"),
+		retries:      r.NewCounterVec("workqueue_retries_total", "Total number of retries handled by workqueue.", "name"),
+		unfinishedWork: r.NewGaugeVec("workqueue_unfinished_work_seconds",
+			"How many seconds of work has been done that is in progress and hasn't been observed by work_duration. Large values indicate stuck threads.", "name"),
+		longestRunningProcessor: r.NewGaugeVec("workqueue_longest_running_processor_seconds",
+			"How many seconds the longest running processor for workqueue has been running.", "name"),
+	}
+}
+
+func (p *workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.depth.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.adds.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return p.latency.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return p.workDuration.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.retries.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.unfinishedWork.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.longestRunningProcessor.WithLabelValues(name)
+}
+
+type requestLatencyMetric struct {
+	latency *SummaryVec
+}
+
+// NewRequestLatencyMetric returns a metrics.LatencyMetric recording rest
+// client request latencies into r, partitioned by verb and url.
+func NewRequestLatencyMetric(r *Registry) metrics.LatencyMetric {
+	return &requestLatencyMetric{
+		latency: r.NewSummaryVec("rest_client_request_latency_seconds", "Request latency in seconds. Broken down by verb and URL.", "verb", "url"),
+	}
+}
+
+func (m *requestLatencyMetric) Observe(verb string, u url.URL, latency time.Duration) {
+	m.latency.WithLabelValues(verb, u.String()).Observe(latency.Seconds())
+}
+
+type requestResultMetric struct {
+	results *CounterVec
+}
+
+// NewRequestResultMetric returns a metrics.ResultMetric counting rest client
+// requests into r, partitioned by status code, method and host.
+func NewRequestResultMetric(r *Registry) metrics.ResultMetric {
+	return &requestResultMetric{
+		results: r.NewCounterVec("rest_client_requests_total", "Number of HTTP requests, partitioned by status code, method, and host.", "code", "method", "host"),
+	}
+}
+
+func (m *requestResultMetric) Increment(code, method, host string) {
+	m.results.WithLabelValues(code, method, host).Inc()
+}
+
+type requestRetryMetric struct {
+	retries *CounterVec
+}
+
+// NewRequestRetryMetric returns a metrics.RetryMetric counting rest client
+// request retries into r, partitioned by status code, method and host.
+func NewRequestRetryMetric(r *Registry) metrics.RetryMetric {
+	return &requestRetryMetric{
+		retries: r.NewCounterVec("rest_client_request_retries_total", "Number of request retries, partitioned by status code, method, and host.", "code", "method", "host"),
+	}
+}
+
+func (m *requestRetryMetric) IncrementRetry(code, method, host string) {
+	m.retries.WithLabelValues(code, method, host).Inc()
+}
+
+type rateLimiterWaitMetric struct {
+	wait *SummaryVec
+}
+
+// NewRateLimiterWaitMetric returns a metrics.RateLimiterMetric recording how
+// long rest client requests waited for the client side rate limiter into r,
+// partitioned by rate limit class.
+func NewRateLimiterWaitMetric(r *Registry) metrics.RateLimiterMetric {
+	return &rateLimiterWaitMetric{
+		wait: r.NewSummaryVec("rest_client_rate_limiter_wait_seconds", "How long requests waited for the client side rate limiter in seconds. Broken down by rate limit class.", "class"),
+	}
+}
+
+func (m *rateLimiterWaitMetric) ObserveWait(class string, wait time.Duration) {
+	m.wait.WithLabelValues(class).Observe(wait.Seconds())
+}
+
+type reflectorMetricsProvider struct {
+	lists               *CounterVec
+	listDuration        *SummaryVec
+	itemsInList         *SummaryVec
+	watches             *CounterVec
+	watchErrors         *CounterVec
+	shortWatches        *CounterVec
+	watchDuration       *SummaryVec
+	itemsInWatch        *SummaryVec
+	lastResourceVersion *GaugeVec
+}
+
+// NewReflectorMetricsProvider returns a cache.MetricsProvider recording into
+// r, with the reflector name as the "name" label.
+func NewReflectorMetricsProvider(r *Registry) cache.MetricsProvider {
+	return &reflectorMetricsProvider{
+		lists:               r.NewCounterVec("reflector_lists_total", "Total number of API lists done by the reflectors.", "name"),
+		listDuration:        r.NewSummaryVec("reflector_list_duration_seconds", "How long an API list takes to return and decode for the reflectors.", "name"),
+		itemsInList:         r.NewSummaryVec("reflector_items_per_list", "How many items an API list returns to the reflectors.", "name"),
+		watches:             r.NewCounterVec("reflector_watches_total", "Total number of API watches done by the reflectors.", "name"),
+		watchErrors:         r.NewCounterVec("reflector_watch_errors_total", "Total number of API watches that failed to start or ended with an error.", "name"),
+		shortWatches:        r.NewCounterVec("reflector_short_watches_total", "Total number of short API watches done by the reflectors.", "name"),
+		watchDuration:       r.NewSummaryVec("reflector_watch_duration_seconds", "How long an API watch takes to return and decode for the reflectors.", "name"),
+		itemsInWatch:        r.NewSummaryVec("reflector_items_per_watch", "How many items an API watch returns to the reflectors.", "name"),
+		lastResourceVersion: r.NewGaugeVec("reflector_last_resource_version", "Last resource version seen for the reflectors.", "name"),
+	}
+}
+
+func (p *reflectorMetricsProvider) NewListsMetric(name string) cache.CounterMetric {
+	return p.lists.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewListDurationMetric(name string) cache.SummaryMetric {
+	return p.listDuration.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewItemsInListMetric(name string) cache.SummaryMetric {
+	return p.itemsInList.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewWatchesMetric(name string) cache.CounterMetric {
+	return p.watches.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewWatchErrorsMetric(name string) cache.CounterMetric {
+	return p.watchErrors.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewShortWatchesMetric(name string) cache.CounterMetric {
+	return p.shortWatches.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewWatchDurationMetric(name string) cache.SummaryMetric {
+	return p.watchDuration.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewItemsInWatchMetric(name string) cache.SummaryMetric {
+	return p.itemsInWatch.WithLabelValues(name)
+}
+
+func (p *reflectorMetricsProvider) NewLastResourceVersionMetric(name string) cache.GaugeMetric {
+	return p.lastResourceVersion.WithLabelValues(name)
+}
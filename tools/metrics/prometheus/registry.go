@@ -0,0 +1,311 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prometheus implements the metrics hooks of the workqueue, rest
+// client and reflector packages and serves the collected metrics in the
+// Prometheus text exposition format.  It has no dependencies outside of the
+// standard library.
+package prometheus // import "github.com/lavalamp/client-go-flat/tools/metrics/prometheus"
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+	summaryType = "summary"
+)
+
+// Registry holds metric families and writes them in the text exposition
+// format.  It is an http.Handler serving that format.
+type Registry struct {
+	lock     sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// family is a named metric with a fixed set of label names, holding one value
+// per combination of label values.
+type family struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+
+	lock     sync.Mutex
+	children map[string]*value
+}
+
+// value holds the state of a counter, gauge or summary.  For summaries v is
+// the sum of the observations.
+type value struct {
+	labelValues []string
+
+	lock  sync.Mutex
+	v     float64
+	count uint64
+}
+
+func (v *value) add(delta float64) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.v += delta
+}
+
+func (v *value) set(val float64) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.v = val
+}
+
+func (v *value) observe(val float64) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.v += val
+	v.count++
+}
+
+func (v *value) get() (float64, uint64) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return v.v, v.count
+}
+
+// register returns the family with the given name, creating it if needed.  It
+// panics if a family of that name exists with a different type or labels, as
+// that is a programming error.
+func (r *Registry) register(name, help, metricType string, labelNames []string) *family {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if f, exists := r.families[name]; exists {
+		if f.metricType != metricType || strings.Join(f.labelNames, ",") != strings.Join(labelNames, ",") {
+			panic(fmt.Sprintf("metric %q is already registered as a %s with labels %v", name, f.metricType, f.labelNames))
+		}
+		return f
+	}
+	f := &family{
+		name:       name,
+		help:       help,
+		metricType: metricType,
+		labelNames: labelNames,
+		children:   map[string]*value{},
+	}
+	r.families[name] = f
+	return f
+}
+
+func (f *family) with(labelValues []string) *value {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metric %q has %d labels, got %d values", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	v, exists := f.children[key]
+	if !exists {
+		v = &value{labelValues: append([]string(nil), labelValues...)}
+		f.children[key] = v
+	}
+	return v
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct{ f *family }
+
+// Counter is a value that only ever goes up.
+type Counter struct{ v *value }
+
+// NewCounterVec registers a counter family with the given label names.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{r.register(name, help, counterType, labelNames)}
+}
+
+// WithLabelValues returns the counter for the given label values, which must
+// match the label names in number and order.
+func (c *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return &Counter{c.f.with(labelValues)}
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add adds delta, which must not be negative, to the counter.
+func (c *Counter) Add(delta float64) { c.v.add(delta) }
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct{ f *family }
+
+// Gauge is a value that can arbitrarily go up and down.
+type Gauge struct{ v *value }
+
+// NewGaugeVec registers a gauge family with the given label names.
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{r.register(name, help, gaugeType, labelNames)}
+}
+
+// WithLabelValues returns the gauge for the given label values, which must
+// match the label names in number and order.
+func (g *GaugeVec) WithLabelValues(labelValues ...string) *Gauge {
+	return &Gauge{g.f.with(labelValues)}
+}
+
+// Inc adds one to the gauge.
+func (g *Gauge) Inc() { g.v.add(1) }
+
+// Dec subtracts one from the gauge.
+func (g *Gauge) Dec() { g.v.add(-1) }
+
+// Set sets the gauge to val.
+func (g *Gauge) Set(val float64) { g.v.set(val) }
+
+// SummaryVec is a summary partitioned by labels.
+type SummaryVec struct{ f *family }
+
+// Summary tracks the count and sum of observations.  It does not compute
+// quantiles.
+type Summary struct{ v *value }
+
+// NewSummaryVec registers a summary family with the given label names.
+func (r *Registry) NewSummaryVec(name, help string, labelNames ...string) *SummaryVec {
+	return &SummaryVec{r.register(name, help, summaryType, labelNames)}
+}
+
+// WithLabelValues returns the summary for the given label values, which must
+// match the label names in number and order.
+func (s *SummaryVec) WithLabelValues(labelValues ...string) *Summary {
+	return &Summary{s.f.with(labelValues)}
+}
+
+// Observe adds an observation to the summary.
+func (s *Summary) Observe(val float64) { s.v.observe(val) }
+
+// WriteTo writes all metrics in the text exposition format, with families
+// sorted by name and values sorted by label values.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.lock.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	families := make([]*family, len(names))
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.lock.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, f := range families {
+		f.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP writes all metrics in the text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.lock.Lock()
+	keys := make([]string, 0, len(f.children))
+	for key := range f.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	children := make([]*value, len(keys))
+	for i, key := range keys {
+		children[i] = f.children[key]
+	}
+	f.lock.Unlock()
+	if len(children) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.metricType)
+	for _, child := range children {
+		labels := f.labels(child.labelValues)
+		v, count := child.get()
+		if f.metricType == summaryType {
+			fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels, formatFloat(v))
+			fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels, count)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s %s\n", f.name, labels, formatFloat(v))
+	}
+}
+
+func (f *family) labels(labelValues []string) string {
+	if len(labelValues) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labelValues))
+	for i, v := range labelValues {
+		pairs[i] = fmt.Sprintf("%s=\"%s\"", f.labelNames[i], escapeLabelValue(v))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelValueEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
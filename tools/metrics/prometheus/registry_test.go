@@ -0,0 +1,143 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	"github.com/lavalamp/client-go-flat/tools/metrics"
+	"github.com/lavalamp/client-go-flat/util/workqueue"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests.\nBy code.", "code")
+	requests.WithLabelValues("500").Inc()
+	requests.WithLabelValues("200").Add(2)
+	inFlight := r.NewGaugeVec("in_flight", "In flight.")
+	inFlight.WithLabelValues().Inc()
+	inFlight.WithLabelValues().Inc()
+	inFlight.WithLabelValues().Dec()
+	latency := r.NewSummaryVec("latency_seconds", "Latency.", "path")
+	latency.WithLabelValues(`a"b\c`).Observe(0.5)
+	latency.WithLabelValues(`a"b\c`).Observe(1.5)
+	r.NewGaugeVec("unused", "Never set.", "name")
+	r.NewGaugeVec("infinite", "Infinite.").WithLabelValues().Set(math.Inf(1))
+
+	expected := `# HELP in_flight In flight.
+# TYPE in_flight gauge
+in_flight 1
+# HELP infinite Infinite.
+# TYPE infinite gauge
+infinite +Inf
+# HELP latency_seconds Latency.
+# TYPE latency_seconds summary
+latency_seconds_sum{path="a\"b\\c"} 2
+latency_seconds_count{path="a\"b\\c"} 2
+# HELP requests_total Requests.\nBy code.
+# TYPE requests_total counter
+requests_total{code="200"} 2
+requests_total{code="500"} 1
+`
+	buf := &bytes.Buffer{}
+	n, err := r.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+}
+
+func TestRegistryConflictingRegistration(t *testing.T) {
+	r := NewRegistry()
+	first := r.NewCounterVec("foo", "Foo.", "name")
+	if second := r.NewCounterVec("foo", "Foo.", "name"); second.f != first.f {
+		t.Errorf("expected the same family for identical registrations")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic registering foo as a gauge")
+		}
+	}()
+	r.NewGaugeVec("foo", "Foo.", "name")
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	Register(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	q := workqueue.NewNamed("test")
+	defer q.ShutDown()
+	q.Add("foo")
+	q.Get()
+	metrics.RequestResult.Increment("200", "GET", "example.com")
+	metrics.RequestLatency.Observe("GET", url.URL{Scheme: "https", Host: "example.com", Path: "/api"}, time.Second)
+
+	longestRunning := regexp.MustCompile(`workqueue_longest_running_processor_seconds\{name="test"\} (\S+)`)
+	var body string
+	err := wait.PollImmediate(100*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+		if resp.Header.Get("Content-Type") != ContentType {
+			t.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return false, err
+		}
+		body = string(data)
+		match := longestRunning.FindStringSubmatch(body)
+		if match == nil {
+			return false, nil
+		}
+		seconds, err := strconv.ParseFloat(match[1], 64)
+		return seconds > 0, err
+	})
+	if err != nil {
+		t.Fatalf("expected the longest running processor to be reported: %v\n%s", err, body)
+	}
+
+	for _, expected := range []string{
+		`workqueue_adds_total{name="test"} 1`,
+		`workqueue_depth{name="test"} 0`,
+		`rest_client_requests_total{code="200",method="GET",host="example.com"} 1`,
+		`rest_client_request_latency_seconds_count{verb="GET",url="https://example.com/api"} 1`,
+	} {
+		if !bytes.Contains([]byte(body), []byte(expected)) {
+			t.Errorf("expected %q in:\n%s", expected, body)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pager provides a helper for breaking large list calls into a
+// sequence of smaller pages using the limit and continue list options.
+package pager // import "github.com/lavalamp/client-go-flat/tools/pager"
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/api/errors"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/api/meta"
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+)
+
+const defaultPageSize = 500
+
+// ListPageFunc returns a list object for the given list options.
+type ListPageFunc func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error)
+
+// SimplePageFunc adapts a context-less list function into one that accepts a context.
+func SimplePageFunc(fn func(opts metav1.ListOptions) (runtime.Object, error)) ListPageFunc {
+	return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return fn(opts)
+	}
+}
+
+// ListPager assists client code in breaking large list queries into multiple
+// smaller chunks of PageSize or smaller. PageFn is expected to accept a
+// metav1.ListOptions that supports paging and return a list. The pager does
+// not alter the field or label selectors on the initial options list.
+type ListPager struct {
+	PageSize int64
+	PageFn   ListPageFunc
+
+	// FullListIfExpired makes the pager restart with a single unpaged list
+	// when the server reports that a continue token is no longer valid.
+	FullListIfExpired bool
+}
+
+// New creates a new pager from the provided pager function using the default
+// options. It will fall back to a full list if an expiration error is encountered
+// as a last resort.
+func New(fn ListPageFunc) *ListPager {
+	return &ListPager{
+		PageSize:          defaultPageSize,
+		PageFn:            fn,
+		FullListIfExpired: true,
+	}
+}
+
+// List returns a single list object, but attempts to retrieve smaller chunks from the
+// server to reduce the impact on the server. If the chunk attempt fails, it will load
+// the full list instead. The returned object has the type of the first page and
+// contains the items of every page, with the resource version of the first page.
+func (p *ListPager) List(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+	if options.Limit == 0 {
+		options.Limit = p.PageSize
+	}
+	var list runtime.Object
+	var listMeta meta.List
+	var items []runtime.Object
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		obj, err := p.PageFn(ctx, options)
+		if err != nil {
+			if !p.FullListIfExpired || !isExpired(err) || len(options.Continue) == 0 {
+				return nil, err
+			}
+			// the continue token expired while we were paging, fall back to a full list
+			options.Limit = 0
+			options.Continue = ""
+			return p.PageFn(ctx, options)
+		}
+		m, err := meta.ListAccessor(obj)
+		if err != nil {
+			return nil, fmt.Errorf("returned object must be a list: %v", err)
+		}
+		next := continueFor(m)
+
+		// return the object as is if the server returned everything in one page
+		if len(next) == 0 && list == nil {
+			return obj, nil
+		}
+
+		page, err := meta.ExtractList(obj)
+		if err != nil {
+			return nil, err
+		}
+		if list == nil {
+			list, listMeta = obj, m
+			items = make([]runtime.Object, 0, int64(len(page))+options.Limit)
+		}
+		items = append(items, page...)
+
+		if len(next) == 0 {
+			if err := meta.SetList(list, items); err != nil {
+				return nil, err
+			}
+			if l, ok := listMeta.(metav1.ListInterface); ok {
+				l.SetContinue("")
+			}
+			return list, nil
+		}
+		options.Continue = next
+	}
+}
+
+// isExpired returns true if err indicates that a continue token can no longer be used.
+func isExpired(err error) bool {
+	return errors.IsResourceExpired(err) || errors.IsGone(err)
+}
+
+// continueFor returns the continue token of a list, or "" if the list does
+// not support paging.
+func continueFor(m meta.List) string {
+	if l, ok := m.(metav1.ListInterface); ok {
+		return l.GetContinue()
+	}
+	return ""
+}
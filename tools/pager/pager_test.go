@@ -0,0 +1,156 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/api/errors"
+	metav1 "github.com/lavalamp/client-go-flat/apimachinery/pkg/apis/meta/v1"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/runtime"
+	"github.com/lavalamp/client-go-flat/pkg/api/v1"
+)
+
+// testPager serves a fixed set of pods in pages of the requested limit,
+// using the index of the next pod as the continue token.
+type testPager struct {
+	t        *testing.T
+	pods     int
+	expire   bool
+	requests []metav1.ListOptions
+}
+
+func (p *testPager) List(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+	p.requests = append(p.requests, options)
+	start := 0
+	if len(options.Continue) > 0 {
+		if p.expire {
+			return nil, errors.NewResourceExpired("continue token expired")
+		}
+		if _, err := fmt.Sscanf(options.Continue, "%d", &start); err != nil {
+			p.t.Fatalf("unexpected continue token %q", options.Continue)
+		}
+	}
+	end := p.pods
+	if options.Limit > 0 && start+int(options.Limit) < end {
+		end = start + int(options.Limit)
+	}
+	list := &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}
+	for i := start; i < end; i++ {
+		list.Items = append(list.Items, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i)}})
+	}
+	if end < p.pods {
+		list.Continue = fmt.Sprintf("%d", end)
+	}
+	return list, nil
+}
+
+func podNames(t *testing.T, obj runtime.Object) []string {
+	list, ok := obj.(*v1.PodList)
+	if !ok {
+		t.Fatalf("expected a *v1.PodList, got %T", obj)
+	}
+	names := []string{}
+	for _, pod := range list.Items {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestListPagerPages(t *testing.T) {
+	source := &testPager{t: t, pods: 5}
+	p := New(source.List)
+	p.PageSize = 2
+
+	obj, err := p.List(context.Background(), metav1.ListOptions{LabelSelector: "app=foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := podNames(t, obj); !reflect.DeepEqual(names, []string{"pod-0", "pod-1", "pod-2", "pod-3", "pod-4"}) {
+		t.Errorf("unexpected pods: %v", names)
+	}
+	list := obj.(*v1.PodList)
+	if list.ResourceVersion != "10" || list.Continue != "" {
+		t.Errorf("unexpected list metadata: %#v", list.ListMeta)
+	}
+	if len(source.requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(source.requests))
+	}
+	for i, options := range source.requests {
+		if options.Limit != 2 || options.LabelSelector != "app=foo" {
+			t.Errorf("request %d: unexpected options %#v", i, options)
+		}
+	}
+	if source.requests[1].Continue != "2" || source.requests[2].Continue != "4" {
+		t.Errorf("unexpected continue tokens: %#v", source.requests)
+	}
+}
+
+func TestListPagerSinglePage(t *testing.T) {
+	source := &testPager{t: t, pods: 2}
+	obj, err := New(source.List).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := podNames(t, obj); len(names) != 2 {
+		t.Errorf("unexpected pods: %v", names)
+	}
+	if len(source.requests) != 1 || source.requests[0].Limit != defaultPageSize {
+		t.Errorf("expected a single request with the default page size, got %#v", source.requests)
+	}
+}
+
+func TestListPagerExpired(t *testing.T) {
+	source := &testPager{t: t, pods: 5, expire: true}
+	p := New(source.List)
+	p.PageSize = 2
+
+	obj, err := p.List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := podNames(t, obj); len(names) != 5 {
+		t.Errorf("expected the full list after expiry, got %v", names)
+	}
+	last := source.requests[len(source.requests)-1]
+	if last.Limit != 0 || last.Continue != "" {
+		t.Errorf("expected an unpaged fallback list, got %#v", last)
+	}
+
+	source = &testPager{t: t, pods: 5, expire: true}
+	p = New(source.List)
+	p.PageSize = 2
+	p.FullListIfExpired = false
+	if _, err := p.List(context.Background(), metav1.ListOptions{}); !errors.IsResourceExpired(err) {
+		t.Errorf("expected an expired error, got %v", err)
+	}
+}
+
+func TestListPagerCancelled(t *testing.T) {
+	source := &testPager{t: t, pods: 5}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(source.List).List(ctx, metav1.ListOptions{}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(source.requests) != 0 {
+		t.Errorf("expected no requests, got %d", len(source.requests))
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	utilnet "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/net"
+	"github.com/lavalamp/client-go-flat/util/connrotation"
 )
 
 // TlsTransportCache caches TLS http.RoundTrippers different configurations. The
@@ -31,58 +32,117 @@ import (
 // the config has no custom TLS options, http.DefaultTransport is returned.
 type tlsTransportCache struct {
 	mu         sync.Mutex
-	transports map[string]*http.Transport
+	transports map[string]http.RoundTripper
 }
 
-const idleConnsPerHost = 25
+const (
+	idleConnsPerHost = 25
 
-var tlsCache = &tlsTransportCache{transports: make(map[string]*http.Transport)}
+	defaultTCPKeepAlive = 30 * time.Second
+)
+
+var tlsCache = &tlsTransportCache{transports: make(map[string]http.RoundTripper)}
 
 func (c *tlsTransportCache) get(config *Config) (http.RoundTripper, error) {
-	key, err := tlsConfigKey(config)
+	key, canCache, err := tlsConfigKey(config)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ensure we only create a single transport for the given TLS options
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if canCache {
+		// Ensure we only create a single transport for the given TLS options
+		c.mu.Lock()
+		defer c.mu.Unlock()
 
-	// See if we already have a custom transport for this config
-	if t, ok := c.transports[key]; ok {
-		return t, nil
+		// See if we already have a custom transport for this config
+		if t, ok := c.transports[key]; ok {
+			return t, nil
+		}
 	}
 
 	// Get the TLS options for this client config
-	tlsConfig, err := TLSConfigFor(config)
+	tlsConfig, dynamicCert, err := tlsConfigFor(config)
 	if err != nil {
 		return nil, err
 	}
-	// The options didn't require a custom TLS config
-	if tlsConfig == nil {
+	// The options didn't require a custom TLS config, dialer or proxy
+	if tlsConfig == nil && config.TCPKeepAlive == 0 && config.Dial == nil && config.ReadIdleTimeout == 0 && config.Proxy == nil && config.ProxyURL == nil {
 		return http.DefaultTransport, nil
 	}
 
-	// Cache a single transport for these options
-	c.transports[key] = utilnet.SetTransportDefaults(&http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
+	proxy := http.ProxyFromEnvironment
+	switch {
+	case config.Proxy != nil:
+		proxy = config.Proxy
+	case config.ProxyURL != nil:
+		proxy = http.ProxyURL(config.ProxyURL)
+	}
+
+	keepAlive := defaultTCPKeepAlive
+	if config.TCPKeepAlive != 0 {
+		keepAlive = config.TCPKeepAlive
+	}
+
+	dial := config.Dial
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: keepAlive,
+		}).Dial
+	}
+	// A client certificate read from files is rotated by closing every
+	// connection presenting the previous one
+	var dialer *connrotation.Dialer
+	if dynamicCert != nil {
+		dialer = connrotation.NewDialer(dial)
+		dial = dialer.Dial
+	}
+
+	transport := utilnet.SetTransportDefaultsWithHealthCheck(&http.Transport{
+		Proxy:               proxy,
 		TLSHandshakeTimeout: 10 * time.Second,
 		TLSClientConfig:     tlsConfig,
 		MaxIdleConnsPerHost: idleConnsPerHost,
-		Dial: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).Dial,
-	})
-	return c.transports[key], nil
+		Dial:                dial,
+	}, config.ReadIdleTimeout, config.PingTimeout)
+	var t http.RoundTripper = transport
+	if dynamicCert != nil {
+		t = newCertRotationRoundTripper(dynamicCert, transport, dialer)
+	}
+	if canCache {
+		// Cache a single transport for these options
+		c.transports[key] = t
+	}
+	return t, nil
 }
 
-// tlsConfigKey returns a unique key for tls.Config objects returned from TLSConfigFor
-func tlsConfigKey(c *Config) (string, error) {
+// tlsConfigKey returns a unique key for tls.Config objects returned from TLSConfigFor,
+// and the dialer and proxy used with them. Configs with a certificate callback, a
+// dial func or a proxy func can't be compared, and so can't be cached, unlike
+// configs with a proxy URL, or with the callback or dial func in a holder.
+func tlsConfigKey(c *Config) (string, bool, error) {
 	// Make sure ca/key/cert content is loaded
 	if err := loadTLSFiles(c); err != nil {
-		return "", err
+		return "", false, err
+	}
+	if (c.HasCertCallback() && c.TLS.GetCertHolder == nil) || (c.Dial != nil && c.DialHolder == nil) || c.Proxy != nil {
+		// cannot determine equality for functions
+		return "", false, nil
+	}
+	// A client certificate read from files is reloaded when they change, so
+	// the files rather than their current content identify it
+	var certFile, keyFile string
+	if certFromFiles(c) {
+		certFile, keyFile = c.TLS.CertFile, c.TLS.KeyFile
+	}
+	var proxyURL string
+	if c.ProxyURL != nil {
+		proxyURL = c.ProxyURL.String()
+	}
+	// Only include the things that actually affect the tls.Config, the dialer, the proxy or the http2 health checks
+	var pingTimeout time.Duration
+	if c.ReadIdleTimeout != 0 {
+		pingTimeout = c.PingTimeout
 	}
-	// Only include the things that actually affect the tls.Config
-	return fmt.Sprintf("%v/%x/%x/%x", c.TLS.Insecure, c.TLS.CAData, c.TLS.CertData, c.TLS.KeyData), nil
+	return fmt.Sprintf("%v/%x/%x/%x/%s/%s/%p/%v/%p/%v/%v/%s", c.TLS.Insecure, c.TLS.CAData, c.TLS.CertData, c.TLS.KeyData, certFile, keyFile, c.TLS.GetCertHolder, c.TCPKeepAlive, c.DialHolder, c.ReadIdleTimeout, pingTimeout, proxyURL), true, nil
 }
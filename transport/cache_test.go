@@ -17,8 +17,17 @@ limitations under the License.
 package transport
 
 import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	certutil "github.com/lavalamp/client-go-flat/util/cert"
 )
 
 func TestTLSConfigKey(t *testing.T) {
@@ -33,16 +42,20 @@ func TestTLSConfigKey(t *testing.T) {
 	}
 	for nameA, valueA := range identicalConfigurations {
 		for nameB, valueB := range identicalConfigurations {
-			keyA, err := tlsConfigKey(valueA)
+			keyA, canCacheA, err := tlsConfigKey(valueA)
 			if err != nil {
 				t.Errorf("Unexpected error for %q: %v", nameA, err)
 				continue
 			}
-			keyB, err := tlsConfigKey(valueB)
+			keyB, canCacheB, err := tlsConfigKey(valueB)
 			if err != nil {
 				t.Errorf("Unexpected error for %q: %v", nameB, err)
 				continue
 			}
+			if !canCacheA || !canCacheB {
+				t.Errorf("Expected %q and %q to be cacheable", nameA, nameB)
+				continue
+			}
 			if keyA != keyB {
 				t.Errorf("Expected identical cache keys for %q and %q, got:\n\t%s\n\t%s", nameA, nameB, keyA, keyB)
 				continue
@@ -51,6 +64,8 @@ func TestTLSConfigKey(t *testing.T) {
 	}
 
 	// Make sure config fields that affect the tls config affect the cache key
+	dial := (&net.Dialer{}).Dial
+	dialHolders := []*DialHolder{{Dial: dial}, {Dial: dial}}
 	uniqueConfigurations := map[string]*Config{
 		"no tls":   {},
 		"insecure": {TLS: TLSConfig{Insecure: true}},
@@ -87,6 +102,14 @@ func TestTLSConfigKey(t *testing.T) {
 				KeyData:  []byte{1},
 			},
 		},
+		"keepalive 1m":           {TCPKeepAlive: time.Minute},
+		"keepalive disabled":     {TCPKeepAlive: -1},
+		"read idle 30s":          {ReadIdleTimeout: 30 * time.Second},
+		"read idle 30s, ping 5s": {ReadIdleTimeout: 30 * time.Second, PingTimeout: 5 * time.Second},
+		"dial holder 1":          {Dial: dialHolders[0].Dial, DialHolder: dialHolders[0]},
+		"dial holder 2":          {Dial: dialHolders[1].Dial, DialHolder: dialHolders[1]},
+		"proxy 1":                {ProxyURL: &url.URL{Scheme: "socks5", Host: "bastion:1080"}},
+		"proxy 2":                {ProxyURL: &url.URL{Scheme: "http", Host: "bastion:3128"}},
 	}
 	for nameA, valueA := range uniqueConfigurations {
 		for nameB, valueB := range uniqueConfigurations {
@@ -95,16 +118,20 @@ func TestTLSConfigKey(t *testing.T) {
 				continue
 			}
 
-			keyA, err := tlsConfigKey(valueA)
+			keyA, canCacheA, err := tlsConfigKey(valueA)
 			if err != nil {
 				t.Errorf("Unexpected error for %q: %v", nameA, err)
 				continue
 			}
-			keyB, err := tlsConfigKey(valueB)
+			keyB, canCacheB, err := tlsConfigKey(valueB)
 			if err != nil {
 				t.Errorf("Unexpected error for %q: %v", nameB, err)
 				continue
 			}
+			if !canCacheA || !canCacheB {
+				t.Errorf("Expected %q and %q to be cacheable", nameA, nameB)
+				continue
+			}
 			if keyA == keyB {
 				t.Errorf("Expected unique cache keys for %q and %q, got:\n\t%s\n\t%s", nameA, nameB, keyA, keyB)
 				continue
@@ -112,3 +139,207 @@ func TestTLSConfigKey(t *testing.T) {
 		}
 	}
 }
+
+func TestTLSCacheDialer(t *testing.T) {
+	cache := &tlsTransportCache{transports: make(map[string]http.RoundTripper)}
+	if rt, err := cache.get(&Config{}); err != nil || rt != http.DefaultTransport {
+		t.Errorf("expected the default transport without options, got %v: %v", rt, err)
+	}
+	rt, err := cache.get(&Config{TCPKeepAlive: time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rt == http.DefaultTransport {
+		t.Errorf("expected a dedicated transport for a custom keep-alive period")
+	}
+	if again, _ := cache.get(&Config{TCPKeepAlive: time.Minute}); again != rt {
+		t.Errorf("expected the transport to be cached")
+	}
+
+	proxyURL, _ := url.Parse("socks5://bastion:1080")
+	rt, err = cache.get(&Config{Proxy: http.ProxyURL(proxyURL)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, _ := http.NewRequest("GET", "https://localhost", nil)
+	if u, err := rt.(*http.Transport).Proxy(req); err != nil || u != proxyURL {
+		t.Errorf("expected the transport to use the configured proxy, got %v: %v", u, err)
+	}
+	if again, _ := cache.get(&Config{Proxy: http.ProxyURL(proxyURL)}); again == rt {
+		t.Errorf("expected a transport with a proxy func not to be cached")
+	}
+
+	rt, err = cache.get(&Config{ProxyURL: proxyURL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u, err := rt.(*http.Transport).Proxy(req); err != nil || u.String() != proxyURL.String() {
+		t.Errorf("expected the transport to use the configured proxy, got %v: %v", u, err)
+	}
+	sameProxyURL, _ := url.Parse("socks5://bastion:1080")
+	if again, _ := cache.get(&Config{ProxyURL: sameProxyURL}); again != rt {
+		t.Errorf("expected transports with the same proxy URL to be shared")
+	}
+	otherProxyURL, _ := url.Parse("socks5://other:1080")
+	if other, _ := cache.get(&Config{ProxyURL: otherProxyURL}); other == rt {
+		t.Errorf("expected transports with different proxy URLs not to be shared")
+	}
+
+	getCert := func() (*tls.Certificate, error) { return nil, nil }
+	rt, err = cache.get(&Config{TLS: TLSConfig{GetCert: getCert}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again, _ := cache.get(&Config{TLS: TLSConfig{GetCert: getCert}}); again == rt {
+		t.Errorf("expected a transport with a certificate callback not to be cached")
+	}
+
+	holder := &GetCertHolder{GetCert: getCert}
+	rt, err = cache.get(&Config{TLS: TLSConfig{GetCert: holder.GetCert, GetCertHolder: holder}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again, _ := cache.get(&Config{TLS: TLSConfig{GetCert: holder.GetCert, GetCertHolder: holder}}); again != rt {
+		t.Errorf("expected transports with the same certificate callback holder to be shared")
+	}
+	other := &GetCertHolder{GetCert: getCert}
+	if again, _ := cache.get(&Config{TLS: TLSConfig{GetCert: other.GetCert, GetCertHolder: other}}); again == rt {
+		t.Errorf("expected transports with different certificate callback holders not to be shared")
+	}
+
+	dial := (&net.Dialer{}).Dial
+	rt, err = cache.get(&Config{Dial: dial})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rt == http.DefaultTransport {
+		t.Errorf("expected a dedicated transport for a custom dial func")
+	}
+	if again, _ := cache.get(&Config{Dial: dial}); again == rt {
+		t.Errorf("expected a transport with a dial func not to be cached")
+	}
+	dialHolder := &DialHolder{Dial: dial}
+	rt, err = cache.get(&Config{Dial: dialHolder.Dial, DialHolder: dialHolder})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again, _ := cache.get(&Config{Dial: dialHolder.Dial, DialHolder: dialHolder}); again != rt {
+		t.Errorf("expected transports with the same dial func holder to be shared")
+	}
+}
+
+// blackholeProxy forwards connections to a server until blackhole is called,
+// and then silently drops everything sent either way, like a dead peer.
+type blackholeProxy struct {
+	listener net.Listener
+	lock     sync.Mutex
+	dropped  bool
+}
+
+func newBlackholeProxy(t *testing.T, server string) *blackholeProxy {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := &blackholeProxy{listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			backend, err := net.Dial("tcp", server)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			go p.forward(conn, backend)
+			go p.forward(backend, conn)
+		}
+	}()
+	return p
+}
+
+func (p *blackholeProxy) forward(dst, src net.Conn) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			return
+		}
+		p.lock.Lock()
+		dropped := p.dropped
+		p.lock.Unlock()
+		if !dropped {
+			dst.Write(buf[:n])
+		}
+	}
+}
+
+func (p *blackholeProxy) blackhole() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.dropped = true
+}
+
+func TestTLSCacheHealthCheck(t *testing.T) {
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := &http.Server{
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// Answer with headers only and never end the body, like a quiet watch
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-req.Context().Done()
+		}),
+	}
+	go server.ServeTLS(l, "", "")
+	defer server.Close()
+	proxy := newBlackholeProxy(t, l.Addr().String())
+	defer proxy.listener.Close()
+
+	cache := &tlsTransportCache{transports: make(map[string]http.RoundTripper)}
+	rt, err := cache.get(&Config{
+		TLS:             TLSConfig{Insecure: true},
+		ReadIdleTimeout: 100 * time.Millisecond,
+		PingTimeout:     100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, _ := http.NewRequest("GET", "https://"+proxy.listener.Addr().String()+"/watch", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("expected an HTTP/2 response, got %s", resp.Proto)
+	}
+
+	proxy.blackhole()
+	done := make(chan error)
+	go func() {
+		_, err := ioutil.ReadAll(resp.Body)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected the dead connection to fail the request")
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the dead connection to be detected by the health check")
+	}
+}
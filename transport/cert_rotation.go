@@ -0,0 +1,163 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/lavalamp/client-go-flat/util/connrotation"
+)
+
+// certCheckInterval is how often the files of a client certificate are checked
+// for changes. It is a variable so tests can shorten it.
+var certCheckInterval = 10 * time.Second
+
+// dynamicClientCert serves a client certificate read from files, and reads it
+// again when the files change, so that certificates rotated on disk are used
+// without restarting the process.
+type dynamicClientCert struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	lock        sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+}
+
+// newDynamicClientCert reads the certificate and key from certFile and keyFile,
+// which are checked for changes at most once per interval.
+func newDynamicClientCert(certFile, keyFile string, interval time.Duration) (*dynamicClientCert, error) {
+	d := &dynamicClientCert{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := d.reloadLocked(); err != nil {
+		return nil, err
+	}
+	d.lastCheck = time.Now()
+	return d, nil
+}
+
+// GetClientCertificate is used as the tls.Config callback of the same name.
+func (d *dynamicClientCert) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return d.current(), nil
+}
+
+// current returns the latest certificate, reading the files again if they
+// changed. If the new files can't be loaded, for example because the key was
+// not written yet, the previous certificate is kept.
+func (d *dynamicClientCert) current() *tls.Certificate {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if now := time.Now(); now.Sub(d.lastCheck) >= d.interval {
+		d.lastCheck = now
+		if err := d.reloadLocked(); err != nil {
+			glog.Warningf("Unable to reload client certificate %s, using the previous one: %v", d.certFile, err)
+		}
+	}
+	return d.cert
+}
+
+func (d *dynamicClientCert) reloadLocked() error {
+	certInfo, err := os.Stat(d.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(d.keyFile)
+	if err != nil {
+		return err
+	}
+	if d.cert != nil && certInfo.ModTime().Equal(d.certModTime) && keyInfo.ModTime().Equal(d.keyModTime) {
+		return nil
+	}
+
+	certData, err := ioutil.ReadFile(d.certFile)
+	if err != nil {
+		return err
+	}
+	keyData, err := ioutil.ReadFile(d.keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		return err
+	}
+	if d.cert != nil {
+		glog.V(2).Infof("Client certificate %s changed, using the new one", d.certFile)
+	}
+	d.cert = &cert
+	d.certModTime = certInfo.ModTime()
+	d.keyModTime = keyInfo.ModTime()
+	return nil
+}
+
+// certRotationRoundTripper closes all the connections of a transport after its
+// client certificate changed, so that new connections present the new one. A
+// connection presents its certificate only once, when it is established, and
+// the connections of HTTP/2 and of watches are not idle while the transport
+// is in use, so the connections in use are closed too. Their requests fail,
+// and watches are started again on new connections.
+type certRotationRoundTripper struct {
+	cert      *dynamicClientCert
+	transport *http.Transport
+	dialer    *connrotation.Dialer
+
+	lock sync.Mutex
+	last *tls.Certificate
+}
+
+// newCertRotationRoundTripper returns a round tripper that sends requests with
+// transport, which must dial its connections with dialer.
+func newCertRotationRoundTripper(cert *dynamicClientCert, transport *http.Transport, dialer *connrotation.Dialer) http.RoundTripper {
+	return &certRotationRoundTripper{cert: cert, transport: transport, dialer: dialer, last: cert.current()}
+}
+
+func (rt *certRotationRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.rotated() {
+		glog.V(2).Infof("Client certificate %s changed, closing all connections", rt.cert.certFile)
+		rt.dialer.CloseAll()
+	}
+	return rt.transport.RoundTrip(req)
+}
+
+// rotated returns true the first time it is called after the certificate
+// changed.
+func (rt *certRotationRoundTripper) rotated() bool {
+	cert := rt.cert.current()
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	if cert == rt.last {
+		return false
+	}
+	rt.last = cert
+	return true
+}
+
+func (rt *certRotationRoundTripper) CancelRequest(req *http.Request) {
+	rt.transport.CancelRequest(req)
+}
+
+func (rt *certRotationRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.transport }
@@ -0,0 +1,199 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	certutil "github.com/lavalamp/client-go-flat/util/cert"
+)
+
+// writeCertKey writes a new self signed certificate for host and its key to
+// certFile and keyFile, and marks them as modified at modTime.
+func writeCertKey(t *testing.T, host, certFile, keyFile string, modTime time.Time) {
+	cert, key, err := certutil.GenerateSelfSignedCertKey(host, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for file, data := range map[string][]byte{certFile: cert, keyFile: key} {
+		if err := ioutil.WriteFile(file, data, 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestCertRotation(t *testing.T) {
+	defer func(interval time.Duration) { certCheckInterval = interval }(certCheckInterval)
+	certCheckInterval = 0
+
+	dir, err := ioutil.TempDir("", "cert-rotation")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	now := time.Now()
+	writeCertKey(t, "first", certFile, keyFile, now.Add(-time.Hour))
+
+	serverCert, err := tls.X509KeyPair([]byte(certData), []byte(keyData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lock sync.Mutex
+	var clients []string
+	server := &http.Server{
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAnyClientCert,
+		},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			lock.Lock()
+			clients = append(clients, req.URL.Path+" "+strings.Split(req.TLS.PeerCertificates[0].Subject.CommonName, "@")[0])
+			lock.Unlock()
+			if req.URL.Path == "/watch" {
+				// stream until the client goes away, like a watch
+				w.(http.Flusher).Flush()
+				<-req.Context().Done()
+			}
+		}),
+	}
+	// serving TLS enables HTTP/2, which the transport uses by default
+	go server.ServeTLS(listener, "", "")
+	defer server.Close()
+
+	rt, err := New(&Config{TLS: TLSConfig{Insecure: true, CertFile: certFile, KeyFile: keyFile}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: rt}
+	get := func(path string) *http.Response {
+		resp, err := client.Get("https://" + listener.Addr().String() + path)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", path, err)
+		}
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("expected an HTTP/2 connection, got %s", resp.Proto)
+		}
+		return resp
+	}
+
+	resp := get("/")
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	watch := get("/watch")
+	defer watch.Body.Close()
+	watchEnded := make(chan error, 1)
+	go func() {
+		_, err := ioutil.ReadAll(watch.Body)
+		watchEnded <- err
+	}()
+
+	writeCertKey(t, "second", certFile, keyFile, now)
+	resp = get("/")
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	select {
+	case <-watchEnded:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the watch on the connection presenting the previous certificate to be closed")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if expected := []string{"/ first", "/watch first", "/ second"}; strings.Join(clients, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected client certificates %v, got %v", expected, clients)
+	}
+}
+
+func TestCertRotationKeepsLoadableCert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cert-rotation")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	now := time.Now()
+	writeCertKey(t, "first", certFile, keyFile, now)
+
+	cert, err := newDynamicClientCert(certFile, keyFile, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a key that can't be loaded yet keeps the previous certificate
+	previous := cert.current()
+	if err := ioutil.WriteFile(keyFile, []byte("partial"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.Chtimes(keyFile, now.Add(time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cert.current() != previous {
+		t.Errorf("expected the previous certificate to be kept")
+	}
+}
+
+func TestTLSConfigForCertFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cert-rotation")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertKey(t, "client", certFile, keyFile, time.Now())
+
+	config := &Config{TLS: TLSConfig{CertFile: certFile, KeyFile: keyFile}}
+	tlsConfig, dynamicCert, err := tlsConfigFor(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dynamicCert == nil || tlsConfig.GetClientCertificate == nil || len(tlsConfig.Certificates) != 0 {
+		t.Errorf("expected the certificate to be reloaded from files, got %#v", tlsConfig)
+	}
+	if len(config.TLS.CertData) != 0 || len(config.TLS.KeyData) != 0 {
+		t.Errorf("expected the certificate files not to be copied into the config")
+	}
+
+	cache := &tlsTransportCache{transports: make(map[string]http.RoundTripper)}
+	rt, err := cache.get(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := rt.(*certRotationRoundTripper); !ok {
+		t.Errorf("expected a transport closing its connections on rotation, got %T", rt)
+	}
+	if again, _ := cache.get(&Config{TLS: TLSConfig{CertFile: certFile, KeyFile: keyFile}}); again != rt {
+		t.Errorf("expected the transport to be cached")
+	}
+}
@@ -16,7 +16,13 @@ limitations under the License.
 
 package transport
 
-import "net/http"
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
 
 // Config holds various options for establishing a transport.
 type Config struct {
@@ -34,6 +40,11 @@ type Config struct {
 	// Bearer token for authentication
 	BearerToken string
 
+	// BearerTokenFile is the path of a file containing the bearer token. The
+	// file is read again when it changes, and the token it holds takes
+	// precedence over BearerToken, which is used if the file can't be read.
+	BearerTokenFile string
+
 	// Impersonate is the config that this Config will impersonate using
 	Impersonate ImpersonationConfig
 
@@ -48,6 +59,54 @@ type Config struct {
 	// config may layer other RoundTrippers on top of the returned
 	// RoundTripper.
 	WrapTransport func(rt http.RoundTripper) http.RoundTripper
+
+	// Proxy is the proxy func to be used for all requests made by this
+	// transport. If Proxy is nil, http.ProxyFromEnvironment is used. If Proxy
+	// returns a nil *URL, no proxy is used. The http, https and socks5 proxy
+	// schemes are supported. Ignored if Transport is set.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// ProxyURL, if set and Proxy is not, is the proxy all requests made by
+	// this transport are sent through. Unlike Proxy it can be compared, so
+	// transports with the same proxy are cached and share their connections.
+	// Ignored if Transport is set.
+	ProxyURL *url.URL
+
+	// TCPKeepAlive is the keep-alive period of the connections dialed by the
+	// transport. The operating system then detects peers that went away, such
+	// as connections silently dropped by a load balancer, and fails the
+	// requests sent on them. If zero, a default of 30 seconds is used. A
+	// negative value disables keep-alives. Ignored if Transport is set.
+	TCPKeepAlive time.Duration
+
+	// ReadIdleTimeout, if set, health checks the HTTP/2 connections of the
+	// transport: a PING frame is sent on a connection that received no frame
+	// for that long, and the connection is closed, failing the requests sent
+	// on it, if the PING isn't answered within PingTimeout. Ignored if
+	// Transport is set.
+	ReadIdleTimeout time.Duration
+
+	// PingTimeout is how long a health check PING waits for its answer before
+	// the connection is closed. If zero, a default of 15 seconds is used.
+	// Ignored if ReadIdleTimeout or Transport isn't set.
+	PingTimeout time.Duration
+
+	// Dial, if set, dials the connections of the transport instead of a
+	// net.Dialer, and TCPKeepAlive is ignored. Ignored if Transport is set.
+	Dial func(network, address string) (net.Conn, error)
+
+	// DialHolder, if set, identifies Dial, which must be its Dial. Configs
+	// with the same holder share a cached transport, while configs with a
+	// Dial but no holder are never cached.
+	DialHolder *DialHolder
+
+	// Tracer, if set, starts a span for every request sent by the transport
+	// and propagates it to the server in the traceparent header.
+	Tracer Tracer
+
+	// RequestLogger, if set, logs every request sent by the transport and its
+	// response, without the values of credential headers.
+	RequestLogger *RequestLoggerConfig
 }
 
 // ImpersonationConfig has all the available impersonation options
@@ -72,7 +131,7 @@ func (c *Config) HasBasicAuth() bool {
 
 // HasTokenAuth returns whether the configuration has token authentication or not.
 func (c *Config) HasTokenAuth() bool {
-	return len(c.BearerToken) != 0
+	return len(c.BearerToken) != 0 || len(c.BearerTokenFile) != 0
 }
 
 // HasCertAuth returns whether the configuration has certificate authentication or not.
@@ -80,6 +139,11 @@ func (c *Config) HasCertAuth() bool {
 	return len(c.TLS.CertData) != 0 || len(c.TLS.CertFile) != 0
 }
 
+// HasCertCallback returns whether the configuration has certificate callback or not.
+func (c *Config) HasCertCallback() bool {
+	return c.TLS.GetCert != nil
+}
+
 // TLSConfig holds the information needed to set up a TLS transport.
 type TLSConfig struct {
 	CAFile   string // Path of the PEM-encoded server trusted root certificates.
@@ -91,4 +155,24 @@ type TLSConfig struct {
 	CAData   []byte // Bytes of the PEM-encoded server trusted root certificates. Supercedes CAFile.
 	CertData []byte // Bytes of the PEM-encoded client certificate. Supercedes CertFile.
 	KeyData  []byte // Bytes of the PEM-encoded client key. Supercedes KeyFile.
+
+	// GetCert is called when the server asks for a client certificate, and
+	// supercedes CertData and KeyData. A nil certificate means none is sent.
+	GetCert func() (*tls.Certificate, error)
+	// GetCertHolder, if set, identifies GetCert, which must be its GetCert.
+	// Configs with the same holder share a cached transport, while configs
+	// with a GetCert but no holder are never cached.
+	GetCertHolder *GetCertHolder
+}
+
+// DialHolder holds a Dial func, so that dial funcs can be compared by the
+// identity of their holders.
+type DialHolder struct {
+	Dial func(network, address string) (net.Conn, error)
+}
+
+// GetCertHolder holds a GetCert callback, so that callbacks can be compared
+// by the identity of their holders.
+type GetCertHolder struct {
+	GetCert func() (*tls.Certificate, error)
 }
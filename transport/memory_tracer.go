@@ -0,0 +1,122 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RecordedSpan is a span that ended, as kept by an InMemoryTracer.
+type RecordedSpan struct {
+	Name        string
+	SpanContext SpanContext
+	// Parent is the span context of the parent span, and is invalid for
+	// the root span of a trace.
+	Parent     SpanContext
+	Attributes map[string]interface{}
+	Start      time.Time
+	End        time.Time
+}
+
+// InMemoryTracer is a Tracer that keeps the spans that ended in memory. It is
+// intended for tests.
+type InMemoryTracer struct {
+	lock  sync.Mutex
+	spans []RecordedSpan
+}
+
+// NewInMemoryTracer returns an empty InMemoryTracer.
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+// StartSpan implements Tracer.
+func (t *InMemoryTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &inMemorySpan{
+		tracer: t,
+		recorded: RecordedSpan{
+			Name:       name,
+			Attributes: map[string]interface{}{},
+			Start:      time.Now(),
+		},
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.recorded.Parent = parent.SpanContext()
+	}
+	span.recorded.SpanContext = SpanContext{
+		TraceID: span.recorded.Parent.TraceID,
+		SpanID:  newSpanID(),
+		Sampled: true,
+	}
+	if !span.recorded.Parent.IsValid() {
+		span.recorded.SpanContext.TraceID = newTraceID()
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// Spans returns the spans that ended, in the order they ended.
+func (t *InMemoryTracer) Spans() []RecordedSpan {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	spans := make([]RecordedSpan, len(t.spans))
+	copy(spans, t.spans)
+	return spans
+}
+
+// Reset forgets all the spans recorded so far.
+func (t *InMemoryTracer) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.spans = nil
+}
+
+type inMemorySpan struct {
+	tracer *InMemoryTracer
+
+	lock     sync.Mutex
+	ended    bool
+	recorded RecordedSpan
+}
+
+func (s *inMemorySpan) SpanContext() SpanContext {
+	return s.recorded.SpanContext
+}
+
+func (s *inMemorySpan) SetAttribute(key string, value interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.ended {
+		s.recorded.Attributes[key] = value
+	}
+}
+
+func (s *inMemorySpan) End() {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.recorded.End = time.Now()
+	s.lock.Unlock()
+
+	s.tracer.lock.Lock()
+	defer s.tracer.lock.Unlock()
+	s.tracer.spans = append(s.tracer.spans, s.recorded)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ghodss/yaml"
+)
+
+// Cassette holds the recorded exchanges of a client with a server.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response the server sent for it.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Data        `json:"body,omitempty"`
+}
+
+// Response is a recorded response. If sending the request failed, Error holds
+// the error instead.
+type Response struct {
+	StatusCode int         `json:"statusCode,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	// Body holds the body in the chunks it was read in, so that streamed
+	// responses such as watches are replayed one event at a time.
+	Body  []Data `json:"body,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Data is a piece of a body. It is serialized as a string if it is valid
+// UTF-8, and as an object with the base64 encoded bytes otherwise.
+type Data []byte
+
+type binaryData struct {
+	Base64 string `json:"base64"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Data) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(d) {
+		return json.Marshal(string(d))
+	}
+	return json.Marshal(binaryData{Base64: base64.StdEncoding.EncodeToString(d)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Data) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*d = Data(s)
+		return nil
+	}
+	var b binaryData
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(b.Base64)
+	if err != nil {
+		return err
+	}
+	*d = decoded
+	return nil
+}
+
+// LoadCassette reads a cassette from a YAML or JSON file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := yaml.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("error reading cassette %s: %v", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to a file, as JSON if its name ends in .json and
+// as YAML otherwise.
+func (c *Cassette) Save(path string) error {
+	var data []byte
+	var err error
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		data, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		data, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
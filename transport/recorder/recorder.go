@@ -0,0 +1,296 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recorder records the HTTP exchanges of a client to a cassette file
+// and replays them, so that code using a client can be tested without a
+// server.
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeRecord sends requests to the server and records them along with
+	// the responses.
+	ModeRecord Mode = iota
+	// ModeReplay answers requests with recorded responses, without sending
+	// them to a server.
+	ModeReplay
+)
+
+// RedactedValue replaces the values of redacted headers in a cassette.
+const RedactedValue = "REDACTED"
+
+// DefaultRedactedHeaders are the headers whose values are not recorded by default.
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Recorder records requests and their responses to a cassette, or replays
+// them. A request is answered by the first recorded interaction with the same
+// method, path and query that did not answer an earlier request; the order of
+// query parameters does not matter. Requests that no interaction matches fail,
+// and are reported by Unmatched.
+type Recorder struct {
+	// RedactHeaders lists the headers of requests and responses whose values
+	// are replaced by RedactedValue when recorded.
+	RedactHeaders []string
+
+	mode      Mode
+	path      string
+	lock      sync.Mutex
+	cassette  *Cassette
+	used      []bool
+	unmatched []string
+}
+
+// New returns a recorder for the cassette at path. In ModeReplay the cassette
+// is loaded, in ModeRecord it is only written by Save.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		RedactHeaders: DefaultRedactedHeaders,
+		mode:          mode,
+		path:          path,
+		cassette:      &Cassette{},
+	}
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown recorder mode %d", mode)
+	}
+	return r, nil
+}
+
+// WrapTransport returns a round tripper that records the requests sent
+// through rt, or replays them without using rt at all. It can be used as the
+// WrapTransport function of a rest.Config.
+func (r *Recorder) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &recorderRoundTripper{recorder: r, rt: rt}
+}
+
+// Save writes the recorded interactions to the cassette. Interactions whose
+// response bodies are still being read, such as open watches, are saved with
+// the part of the body that was read so far.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return errors.New("only a recorder in record mode can be saved")
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// Unmatched returns the requests no recorded interaction matched, as method
+// and URL.
+func (r *Recorder) Unmatched() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.unmatched...)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) *Interaction {
+	interaction := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redact(req.Header),
+			Body:   body,
+		},
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return interaction
+}
+
+func (r *Recorder) replay(req *http.Request) (*Interaction, error) {
+	method, path, query := req.Method, req.URL.Path, normalizeQuery(req.URL.Query())
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Method != method {
+			continue
+		}
+		recorded, err := url.Parse(interaction.Request.URL)
+		if err != nil || recorded.Path != path || normalizeQuery(recorded.Query()) != query {
+			continue
+		}
+		r.used[i] = true
+		return interaction, nil
+	}
+	request := method + " " + req.URL.String()
+	r.unmatched = append(r.unmatched, request)
+	return nil, fmt.Errorf("no recorded interaction matches the request %s", request)
+}
+
+// redact returns a copy of header without the values of RedactHeaders.
+func (r *Recorder) redact(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		redacted[key] = append([]string(nil), values...)
+	}
+	for _, key := range r.RedactHeaders {
+		if values, ok := redacted[http.CanonicalHeaderKey(key)]; ok {
+			for i := range values {
+				values[i] = RedactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+// normalizeQuery encodes query with sorted keys and values.
+func normalizeQuery(query url.Values) string {
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return query.Encode()
+}
+
+type recorderRoundTripper struct {
+	recorder *Recorder
+	rt       http.RoundTripper
+}
+
+func (rt *recorderRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.recorder.mode == ModeReplay {
+		// nothing reads the body in place of a server
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		interaction, err := rt.recorder.replay(req)
+		if err != nil {
+			return nil, err
+		}
+		return replayResponse(req, &interaction.Response)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		clone := *req
+		clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req = &clone
+	}
+	interaction := rt.recorder.record(req, body)
+	resp, err := rt.rt.RoundTrip(req)
+
+	rt.recorder.lock.Lock()
+	defer rt.recorder.lock.Unlock()
+	if err != nil {
+		interaction.Response.Error = err.Error()
+		return nil, err
+	}
+	interaction.Response.StatusCode = resp.StatusCode
+	interaction.Response.Header = rt.recorder.redact(resp.Header)
+	resp.Body = &recordingBody{ReadCloser: resp.Body, recorder: rt.recorder, response: &interaction.Response}
+	return resp, nil
+}
+
+func (rt *recorderRoundTripper) CancelRequest(req *http.Request) {
+	type canceler interface {
+		CancelRequest(*http.Request)
+	}
+	if cr, ok := rt.rt.(canceler); ok && rt.recorder.mode == ModeRecord {
+		cr.CancelRequest(req)
+	}
+}
+
+func (rt *recorderRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.rt }
+
+// recordingBody records the chunks of a response body as they are read.
+type recordingBody struct {
+	io.ReadCloser
+	recorder *Recorder
+	response *Response
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.recorder.lock.Lock()
+		b.response.Body = append(b.response.Body, append(Data(nil), p[:n]...))
+		b.recorder.lock.Unlock()
+	}
+	return n, err
+}
+
+func replayResponse(req *http.Request, recorded *Response) (*http.Response, error) {
+	if len(recorded.Error) != 0 {
+		return nil, errors.New(recorded.Error)
+	}
+	header := http.Header{}
+	for key, values := range recorded.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          &chunkReader{chunks: append([]Data(nil), recorded.Body...)},
+		ContentLength: -1,
+		Request:       req,
+	}, nil
+}
+
+// chunkReader returns at most one chunk per read, the way the body was read
+// when it was recorded.
+type chunkReader struct {
+	chunks []Data
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunks) > 0 && len(r.chunks[0]) == 0 {
+		r.chunks = r.chunks[1:]
+	}
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks[0] = r.chunks[0][n:]
+	return n, nil
+}
+
+func (r *chunkReader) Close() error {
+	return nil
+}
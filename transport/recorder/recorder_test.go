@@ -0,0 +1,207 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	for _, name := range []string{"cassette.yaml", "cassette.json"} {
+		dir, err := ioutil.TempDir("", "recorder")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, name)
+
+		// the server sends the next event of a watch once the previous one was read
+		next := make(chan struct{}, 3)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Set-Cookie", "session=secret")
+			switch {
+			case req.URL.Query().Get("watch") == "true":
+				for i := 0; i < 3; i++ {
+					fmt.Fprintf(w, "{\"event\":%d}\n", i)
+					w.(http.Flusher).Flush()
+					<-next
+				}
+			case req.Method == "POST":
+				body, _ := ioutil.ReadAll(req.Body)
+				w.WriteHeader(http.StatusCreated)
+				w.Write(body)
+			default:
+				w.Write([]byte{0xff, 0xfe, 'x'})
+			}
+		}))
+		defer server.Close()
+
+		recorder, err := New(path, ModeRecord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded := exchange(t, &http.Client{Transport: recorder.WrapTransport(http.DefaultTransport)}, server.URL, "a=1&b=2", func() { next <- struct{}{} })
+		if err := recorder.Save(); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret") || !strings.Contains(string(data), RedactedValue) {
+			t.Errorf("%s: expected the secrets to be redacted:\n%s", name, data)
+		}
+
+		recorder, err = New(path, ModeReplay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: recorder.WrapTransport(nil)}
+		replayed := exchange(t, client, server.URL, "b=2&a=1", func() {})
+		if len(replayed) != 5 {
+			t.Errorf("%s: expected the events of the watch to be replayed one at a time, got %q", name, replayed)
+		}
+		if !reflect.DeepEqual(recorded, replayed) {
+			t.Errorf("%s: expected the replayed responses\n%q\nto match the recorded ones\n%q", name, replayed, recorded)
+		}
+
+		// every interaction answers a single request
+		if _, err := client.Get(server.URL + "/api/v1/pods?a=1&b=2"); err == nil {
+			t.Errorf("%s: expected an error for a request that was already replayed", name)
+		}
+		body := &closeRecorder{Reader: bytes.NewReader([]byte("{}"))}
+		if _, err := client.Post(server.URL+"/api/v1/nodes", "application/json", body); err == nil {
+			t.Errorf("%s: expected an error for an unmatched request", name)
+		}
+		if !body.closed {
+			t.Errorf("%s: expected the body of the unmatched request to be closed", name)
+		}
+		expected := []string{"GET " + server.URL + "/api/v1/pods?a=1&b=2", "POST " + server.URL + "/api/v1/nodes"}
+		if unmatched := recorder.Unmatched(); !reflect.DeepEqual(unmatched, expected) {
+			t.Errorf("%s: expected unmatched requests %v, got %v", name, expected, unmatched)
+		}
+	}
+}
+
+// exchange sends a get, a post and a watch and returns the responses, with
+// the events of the watch as they were read. read is called after every read
+// of the watch.
+func exchange(t *testing.T, client *http.Client, host, query string, read func()) []string {
+	var responses []string
+
+	req, _ := http.NewRequest("GET", host+"/api/v1/pods?"+query, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	responses = append(responses, fmt.Sprintf("%d %q", resp.StatusCode, body))
+
+	resp, err = client.Post(host+"/api/v1/pods", "application/json", bytes.NewReader([]byte(`{"kind":"Pod"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	responses = append(responses, fmt.Sprintf("%d %s", resp.StatusCode, body))
+
+	resp, err = client.Get(host + "/api/v1/pods?watch=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	buf := make([]byte, 1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			responses = append(responses, string(buf[:n]))
+		}
+		read()
+		if err != nil {
+			break
+		}
+	}
+	return responses
+}
+
+func TestRecordError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.yaml")
+
+	recorder, _ := New(path, ModeRecord)
+	failing := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	if _, err := recorder.WrapTransport(failing).RoundTrip(&http.Request{Method: "GET", URL: mustParse("https://localhost/api")}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder, err = New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = recorder.WrapTransport(nil).RoundTrip(&http.Request{Method: "GET", URL: mustParse("https://localhost/api")})
+	if err == nil || err.Error() != "connection refused" {
+		t.Errorf("expected the recorded error, got %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func mustParse(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// closeRecorder records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// redactedValue replaces redacted header values in logs.
+const redactedValue = "<redacted>"
+
+// DefaultRedactedHeaders are the headers whose values are not logged unless a
+// RequestLoggerConfig lists others. A name ending in "-" matches every header
+// with that prefix.
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "Impersonate-"}
+
+// RequestLog describes a request and its response.
+type RequestLog struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	URL    string    `json:"url"`
+
+	RequestHeader        http.Header `json:"requestHeader,omitempty"`
+	RequestBody          string      `json:"requestBody,omitempty"`
+	RequestBodyTruncated bool        `json:"requestBodyTruncated,omitempty"`
+
+	StatusCode            int         `json:"statusCode,omitempty"`
+	ResponseHeader        http.Header `json:"responseHeader,omitempty"`
+	ResponseBody          string      `json:"responseBody,omitempty"`
+	ResponseBodyTruncated bool        `json:"responseBodyTruncated,omitempty"`
+	Error                 string      `json:"error,omitempty"`
+
+	// Latency is the time until the response headers were received.
+	Latency time.Duration `json:"latency"`
+}
+
+// RequestLogSink receives the logs of requests.
+type RequestLogSink interface {
+	Log(entry *RequestLog)
+}
+
+// RequestLogSinkFunc is a function that implements RequestLogSink.
+type RequestLogSinkFunc func(entry *RequestLog)
+
+// Log implements RequestLogSink.
+func (f RequestLogSinkFunc) Log(entry *RequestLog) {
+	f(entry)
+}
+
+// NewJSONRequestLogSink returns a sink that writes every log as a line of JSON to w.
+func NewJSONRequestLogSink(w io.Writer) RequestLogSink {
+	return &jsonRequestLogSink{encoder: json.NewEncoder(w)}
+}
+
+type jsonRequestLogSink struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+func (s *jsonRequestLogSink) Log(entry *RequestLog) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.encoder.Encode(entry); err != nil {
+		glog.Warningf("Failed to write request log: %v", err)
+	}
+}
+
+// NewGlogRequestLogSink returns a sink that writes logs to glog at level.
+func NewGlogRequestLogSink(level glog.Level) RequestLogSink {
+	return glogRequestLogSink(level)
+}
+
+type glogRequestLogSink glog.Level
+
+func (s glogRequestLogSink) Log(entry *RequestLog) {
+	v := glog.V(glog.Level(s))
+	if !v {
+		return
+	}
+	if len(entry.Error) != 0 {
+		v.Infof("%s %s failed in %d milliseconds: %s", entry.Method, entry.URL, entry.Latency.Nanoseconds()/int64(time.Millisecond), entry.Error)
+	} else {
+		v.Infof("%s %s %d in %d milliseconds", entry.Method, entry.URL, entry.StatusCode, entry.Latency.Nanoseconds()/int64(time.Millisecond))
+	}
+	logHeader(v, "Request Headers:", entry.RequestHeader)
+	if len(entry.RequestBody) != 0 {
+		v.Infof("Request Body: %s%s", entry.RequestBody, truncatedSuffix(entry.RequestBodyTruncated))
+	}
+	logHeader(v, "Response Headers:", entry.ResponseHeader)
+	if len(entry.ResponseBody) != 0 {
+		v.Infof("Response Body: %s%s", entry.ResponseBody, truncatedSuffix(entry.ResponseBodyTruncated))
+	}
+}
+
+func logHeader(v glog.Verbose, title string, header http.Header) {
+	if len(header) == 0 {
+		return
+	}
+	v.Info(title)
+	for key, values := range header {
+		for _, value := range values {
+			v.Infof("    %s: %s", key, value)
+		}
+	}
+}
+
+func truncatedSuffix(truncated bool) string {
+	if truncated {
+		return " [truncated]"
+	}
+	return ""
+}
+
+// SampleRate returns a sampling function for RequestLoggerConfig that logs a
+// random fraction of requests.
+func SampleRate(fraction float64) func(*http.Request) bool {
+	return func(*http.Request) bool {
+		return rand.Float64() < fraction
+	}
+}
+
+// RequestLoggerConfig configures the logging of requests.
+type RequestLoggerConfig struct {
+	// Sink receives the logs.
+	Sink RequestLogSink
+
+	// RedactHeaders lists the headers of requests and responses whose values
+	// are not logged. If empty, DefaultRedactedHeaders is used. A name ending
+	// in "-" matches every header with that prefix.
+	RedactHeaders []string
+
+	// MaxBodyBytes is the number of bytes of request and response bodies that
+	// are logged. Longer bodies are truncated. Bodies are not logged if zero.
+	// Logs of requests whose response body is logged are only written once
+	// the body was read to its end or closed.
+	MaxBodyBytes int
+
+	// RedactBody, if set, returns the body to log in place of a request or
+	// response body with the given content type.
+	RedactBody func(contentType string, body []byte) []byte
+
+	// Sample, if set, decides whether a request is logged. All requests are
+	// logged if nil.
+	Sample func(req *http.Request) bool
+}
+
+type requestLoggingRoundTripper struct {
+	config RequestLoggerConfig
+	rt     http.RoundTripper
+}
+
+// NewRequestLoggingRoundTripper logs the requests sent through rt, and their
+// responses, to the sink of config.
+func NewRequestLoggingRoundTripper(config RequestLoggerConfig, rt http.RoundTripper) http.RoundTripper {
+	if len(config.RedactHeaders) == 0 {
+		config.RedactHeaders = DefaultRedactedHeaders
+	}
+	return &requestLoggingRoundTripper{config: config, rt: rt}
+}
+
+func (rt *requestLoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.config.Sink == nil || (rt.config.Sample != nil && !rt.config.Sample(req)) {
+		return rt.rt.RoundTrip(req)
+	}
+
+	entry := &RequestLog{
+		Time:          time.Now(),
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: redactHeader(req.Header, rt.config.RedactHeaders),
+	}
+	if rt.config.MaxBodyBytes > 0 && req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		entry.RequestBody, entry.RequestBodyTruncated = rt.logBody(req.Header, body)
+		req = cloneRequest(req)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := rt.rt.RoundTrip(req)
+	entry.Latency = time.Since(entry.Time)
+	if err != nil {
+		entry.Error = err.Error()
+		rt.config.Sink.Log(entry)
+		return nil, err
+	}
+	entry.StatusCode = resp.StatusCode
+	entry.ResponseHeader = redactHeader(resp.Header, rt.config.RedactHeaders)
+	if rt.config.MaxBodyBytes > 0 && resp.Body != nil {
+		resp.Body = &loggingBody{ReadCloser: resp.Body, rt: rt, header: resp.Header, entry: entry}
+		return resp, nil
+	}
+	rt.config.Sink.Log(entry)
+	return resp, nil
+}
+
+// logBody returns the part of body that is logged, and whether it was truncated.
+func (rt *requestLoggingRoundTripper) logBody(header http.Header, body []byte) (string, bool) {
+	if rt.config.RedactBody != nil {
+		body = rt.config.RedactBody(header.Get("Content-Type"), body)
+	}
+	if len(body) > rt.config.MaxBodyBytes {
+		return string(body[:rt.config.MaxBodyBytes]), true
+	}
+	return string(body), false
+}
+
+func (rt *requestLoggingRoundTripper) CancelRequest(req *http.Request) {
+	if canceler, ok := rt.rt.(requestCanceler); ok {
+		canceler.CancelRequest(req)
+	} else {
+		glog.Errorf("CancelRequest not implemented")
+	}
+}
+
+func (rt *requestLoggingRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.rt }
+
+// loggingBody keeps the start of a response body, and writes the log of the
+// request once the body was read to its end or closed. A watch is closed from
+// another goroutine while it is blocked in Read, so the body is guarded by lock.
+type loggingBody struct {
+	io.ReadCloser
+	rt     *requestLoggingRoundTripper
+	header http.Header
+	entry  *RequestLog
+
+	once sync.Once
+	lock sync.Mutex
+	body []byte
+}
+
+func (b *loggingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.lock.Lock()
+	if remaining := b.rt.config.MaxBodyBytes + 1 - len(b.body); remaining > 0 {
+		if remaining > n {
+			remaining = n
+		}
+		b.body = append(b.body, p[:remaining]...)
+	}
+	b.lock.Unlock()
+	if err != nil {
+		b.log()
+	}
+	return n, err
+}
+
+func (b *loggingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.log()
+	return err
+}
+
+func (b *loggingBody) log() {
+	b.once.Do(func() {
+		b.lock.Lock()
+		body := append([]byte(nil), b.body...)
+		b.lock.Unlock()
+		b.entry.ResponseBody, b.entry.ResponseBodyTruncated = b.rt.logBody(b.header, body)
+		b.rt.config.Sink.Log(b.entry)
+	})
+}
+
+// redactHeader returns a copy of header with the values of the headers in
+// redact replaced. The scheme of redacted authorization headers is kept.
+func redactHeader(header http.Header, redact []string) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		if !isRedactedHeader(key, redact) {
+			redacted[key] = values
+			continue
+		}
+		redactedValues := make([]string, len(values))
+		for i, value := range values {
+			redactedValues[i] = redactedValue
+			if strings.HasSuffix(key, "Authorization") {
+				if space := strings.Index(value, " "); space > 0 {
+					redactedValues[i] = value[:space] + " " + redactedValue
+				}
+			}
+		}
+		redacted[key] = redactedValues
+	}
+	return redacted
+}
+
+func isRedactedHeader(key string, redact []string) bool {
+	key = http.CanonicalHeaderKey(key)
+	for _, name := range redact {
+		name = http.CanonicalHeaderKey(name)
+		if key == name || (strings.HasSuffix(name, "-") && strings.HasPrefix(key, name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+)
+
+func TestRequestLoggerRedactsHeaders(t *testing.T) {
+	var logs []*RequestLog
+	rt := &testRoundTripper{Response: &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Set-Cookie": {"session=secret"}, "Content-Type": {"application/json"}},
+	}}
+	logger := NewRequestLoggingRoundTripper(RequestLoggerConfig{Sink: RequestLogSinkFunc(func(entry *RequestLog) { logs = append(logs, entry) })}, rt)
+
+	req := &http.Request{Method: "GET", URL: &url.URL{Scheme: "https", Host: "localhost", Path: "/api"}, Header: http.Header{
+		"Authorization":            {"Bearer secret"},
+		"Impersonate-User":         {"secret"},
+		"Impersonate-Extra-Scopes": {"secret"},
+		"Accept":                   {"application/json"},
+	}}
+	if _, err := logger.RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected a log, got %d", len(logs))
+	}
+	expected := http.Header{
+		"Authorization":            {"Bearer <redacted>"},
+		"Impersonate-User":         {"<redacted>"},
+		"Impersonate-Extra-Scopes": {"<redacted>"},
+		"Accept":                   {"application/json"},
+	}
+	if !reflect.DeepEqual(logs[0].RequestHeader, expected) {
+		t.Errorf("expected request headers %v, got %v", expected, logs[0].RequestHeader)
+	}
+	expected = http.Header{"Set-Cookie": {"<redacted>"}, "Content-Type": {"application/json"}}
+	if !reflect.DeepEqual(logs[0].ResponseHeader, expected) {
+		t.Errorf("expected response headers %v, got %v", expected, logs[0].ResponseHeader)
+	}
+	if rt.Request.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected the request to be sent with its credentials, got %v", rt.Request.Header)
+	}
+}
+
+func TestRequestLoggerBodies(t *testing.T) {
+	var logs []*RequestLog
+	rt := &testRoundTripper{Response: &http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"kind":"Secret","data":"c2VjcmV0"}`)),
+	}}
+	logger := NewRequestLoggingRoundTripper(RequestLoggerConfig{
+		Sink:         RequestLogSinkFunc(func(entry *RequestLog) { logs = append(logs, entry) }),
+		MaxBodyBytes: 16,
+		RedactBody: func(contentType string, body []byte) []byte {
+			return bytes.Replace(body, []byte("c2VjcmV0"), []byte("xxx"), -1)
+		},
+	}, rt)
+
+	req := &http.Request{Method: "POST", URL: &url.URL{Path: "/api"}, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"kind":"Pod"}`))}
+	resp, err := logger.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent, _ := ioutil.ReadAll(rt.Request.Body); string(sent) != `{"kind":"Pod"}` {
+		t.Errorf("expected the whole body to be sent, got %q", sent)
+	}
+	if len(logs) != 0 {
+		t.Fatalf("expected the log to wait for the response body")
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != `{"kind":"Secret","data":"c2VjcmV0"}` {
+		t.Errorf("expected the whole response body, got %q", body)
+	}
+	resp.Body.Close()
+	if len(logs) != 1 {
+		t.Fatalf("expected a log, got %d", len(logs))
+	}
+	if logs[0].RequestBody != `{"kind":"Pod"}` || logs[0].RequestBodyTruncated {
+		t.Errorf("unexpected request body %q, truncated %t", logs[0].RequestBody, logs[0].RequestBodyTruncated)
+	}
+	if logs[0].ResponseBody != `{"kind":"Secret"` || !logs[0].ResponseBodyTruncated {
+		t.Errorf("unexpected response body %q, truncated %t", logs[0].ResponseBody, logs[0].ResponseBodyTruncated)
+	}
+}
+
+func TestRequestLoggerCloseWhileReading(t *testing.T) {
+	logs := make(chan *RequestLog, 1)
+	r, w := io.Pipe()
+	rt := &testRoundTripper{Response: &http.Response{StatusCode: http.StatusOK, Body: r}}
+	logger := NewRequestLoggingRoundTripper(RequestLoggerConfig{
+		Sink:         RequestLogSinkFunc(func(entry *RequestLog) { logs <- entry }),
+		MaxBodyBytes: 1024,
+	}, rt)
+
+	resp, err := logger.RoundTrip(&http.Request{Method: "GET", URL: &url.URL{Path: "/api"}, Header: http.Header{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// like a watch, which is stopped while it is waiting for the next event
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			if _, err := resp.Body.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+	w.Write([]byte(`{"type":"ADDED"}`))
+	w.Write([]byte(`{"type":"DELETED"}`))
+	resp.Body.Close()
+	w.CloseWithError(io.ErrClosedPipe)
+
+	select {
+	case entry := <-logs:
+		if !strings.HasPrefix(`{"type":"ADDED"}{"type":"DELETED"}`, entry.ResponseBody) {
+			t.Errorf("unexpected response body %q", entry.ResponseBody)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("expected the request to be logged when the body is closed")
+	}
+}
+
+func TestRequestLoggerSampling(t *testing.T) {
+	var logs []*RequestLog
+	rt := &testRoundTripper{Err: errors.New("connection refused")}
+	sample := false
+	logger := NewRequestLoggingRoundTripper(RequestLoggerConfig{
+		Sink:   RequestLogSinkFunc(func(entry *RequestLog) { logs = append(logs, entry) }),
+		Sample: func(*http.Request) bool { return sample },
+	}, rt)
+
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/api"}, Header: http.Header{}}
+	logger.RoundTrip(req)
+	if len(logs) != 0 {
+		t.Errorf("expected the request not to be logged")
+	}
+	sample = true
+	logger.RoundTrip(req)
+	if len(logs) != 1 || logs[0].Error != "connection refused" {
+		t.Errorf("expected the error to be logged, got %v", logs)
+	}
+}
+
+func TestJSONRequestLogSink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	sink := NewJSONRequestLogSink(buf)
+	sink.Log(&RequestLog{Method: "GET", URL: "/api", StatusCode: http.StatusOK})
+	sink.Log(&RequestLog{Method: "DELETE", URL: "/api/v1/pods/foo", StatusCode: http.StatusNotFound})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two lines, got %q", buf.String())
+	}
+	entry := &RequestLog{}
+	if err := json.Unmarshal([]byte(lines[1]), entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Method != "DELETE" || entry.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected log %#v", entry)
+	}
+}
+
+func TestCurlCommandRedactsCredentials(t *testing.T) {
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/api"}, Header: http.Header{"Authorization": {"Bearer secret"}}}
+	if curl := newRequestInfo(req).toCurl(); strings.Contains(curl, "secret") {
+		t.Errorf("expected the token to be redacted, got %s", curl)
+	}
+}
@@ -36,11 +36,16 @@ func HTTPWrappersForConfig(config *Config, rt http.RoundTripper) (http.RoundTrip
 	}
 
 	rt = DebugWrappers(rt)
+	if config.RequestLogger != nil {
+		rt = NewRequestLoggingRoundTripper(*config.RequestLogger, rt)
+	}
 
 	// Set authentication wrappers
 	switch {
 	case config.HasBasicAuth() && config.HasTokenAuth():
 		return nil, fmt.Errorf("username/password or bearer token may be set, but not both")
+	case len(config.BearerTokenFile) != 0:
+		rt = NewBearerTokenFileRoundTripper(config.BearerToken, config.BearerTokenFile, rt)
 	case config.HasTokenAuth():
 		rt = NewBearerAuthRoundTripper(config.BearerToken, rt)
 	case config.HasBasicAuth():
@@ -54,6 +59,9 @@ func HTTPWrappersForConfig(config *Config, rt http.RoundTripper) (http.RoundTrip
 		len(config.Impersonate.Extra) > 0 {
 		rt = NewImpersonatingRoundTripper(config.Impersonate, rt)
 	}
+	if config.Tracer != nil {
+		rt = NewTracingRoundTripper(config.Tracer, rt)
+	}
 	return rt, nil
 }
 
@@ -319,12 +327,13 @@ type requestInfo struct {
 	Duration time.Duration
 }
 
-// newRequestInfo creates a new RequestInfo based on an http request
+// newRequestInfo creates a new RequestInfo based on an http request, without
+// the values of credential headers
 func newRequestInfo(req *http.Request) *requestInfo {
 	return &requestInfo{
 		RequestURL:     req.URL.String(),
 		RequestVerb:    req.Method,
-		RequestHeaders: req.Header,
+		RequestHeaders: redactHeader(req.Header, DefaultRedactedHeaders),
 	}
 }
 
@@ -335,7 +344,7 @@ func (r *requestInfo) complete(response *http.Response, err error) {
 		return
 	}
 	r.ResponseStatus = response.Status
-	r.ResponseHeaders = response.Header
+	r.ResponseHeaders = redactHeader(response.Header, DefaultRedactedHeaders)
 }
 
 // toCurl returns a string that can be run as a command in a terminal (minus the body)
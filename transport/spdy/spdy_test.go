@@ -0,0 +1,176 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spdy
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/httpstream"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/httpstream/spdy"
+	"github.com/lavalamp/client-go-flat/apimachinery/pkg/util/wait"
+	restclient "github.com/lavalamp/client-go-flat/rest"
+)
+
+// fakeSocks5Proxy is a SOCKS5 proxy supporting only unauthenticated CONNECT
+// requests to IPv4 addresses, which records the addresses it connected to.
+type fakeSocks5Proxy struct {
+	listener net.Listener
+
+	lock    sync.Mutex
+	targets []string
+}
+
+func newFakeSocks5Proxy(t *testing.T) *fakeSocks5Proxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := &fakeSocks5Proxy{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go p.serve(t, conn)
+		}
+	}()
+	return p
+}
+
+func (p *fakeSocks5Proxy) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		t.Errorf("failed to read greeting: %v", err)
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, greeting[1])); err != nil {
+		t.Errorf("failed to read methods: %v", err)
+		return
+	}
+	conn.Write([]byte{5, 0})
+
+	request := make([]byte, 10)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		t.Errorf("failed to read connect request: %v", err)
+		return
+	}
+	if request[1] != 1 || request[3] != 1 {
+		t.Errorf("unexpected connect request: %v", request)
+		return
+	}
+	target := net.JoinHostPort(net.IP(request[4:8]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(request[8:]))))
+	p.lock.Lock()
+	p.targets = append(p.targets, target)
+	p.lock.Unlock()
+
+	backend, err := net.Dial("tcp", target)
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer backend.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backend, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, backend)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func (p *fakeSocks5Proxy) Targets() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func TestDialerThroughSocks5Proxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, err := httpstream.Handshake(req, w, []string{"test.v1"}); err != nil {
+			t.Errorf("handshake failed: %v", err)
+			return
+		}
+		conn := spdy.NewResponseUpgrader().UpgradeResponse(w, req, func(httpstream.Stream, <-chan struct{}) error { return nil })
+		if conn == nil {
+			t.Errorf("upgrade failed")
+			return
+		}
+		defer conn.Close()
+		select {
+		case <-conn.CloseChan():
+		case <-time.After(wait.ForeverTestTimeout):
+		}
+	}))
+	defer server.Close()
+
+	proxy := newFakeSocks5Proxy(t)
+	defer proxy.listener.Close()
+	proxyURL := &url.URL{Scheme: "socks5", Host: proxy.listener.Addr().String()}
+
+	config := &restclient.Config{Host: server.URL, Proxy: http.ProxyURL(proxyURL)}
+	rt, upgrader, err := RoundTripperFor(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dialer := NewDialer(upgrader, &http.Client{Transport: rt}, "POST", serverURL)
+
+	conn, protocol, err := dialer.Dial("test.v2", "test.v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	if protocol != "test.v1" {
+		t.Errorf("expected protocol test.v1, got %q", protocol)
+	}
+	if targets := proxy.Targets(); len(targets) != 1 || targets[0] != serverURL.Host {
+		t.Errorf("expected a single connection to %s through the proxy, got %v", serverURL.Host, targets)
+	}
+}
+
+func TestDialerUnsupportedProxyScheme(t *testing.T) {
+	proxyURL := &url.URL{Scheme: "ftp", Host: "proxy.example.com"}
+	config := &restclient.Config{Host: "https://127.0.0.1", Proxy: http.ProxyURL(proxyURL)}
+	rt, upgrader, err := RoundTripperFor(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serverURL, _ := url.Parse(config.Host)
+	dialer := NewDialer(upgrader, &http.Client{Transport: rt}, "POST", serverURL)
+	if _, _, err := dialer.Dial("test.v1"); err == nil {
+		t.Errorf("expected an error for an unsupported proxy scheme")
+	}
+}
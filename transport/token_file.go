@@ -0,0 +1,127 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// tokenFileCheckInterval is how often a token file is checked for changes.
+const tokenFileCheckInterval = 10 * time.Second
+
+// cachedTokenFile holds a bearer token read from a file, and reads it again
+// when the file changes.
+type cachedTokenFile struct {
+	path     string
+	interval time.Duration
+
+	lock      sync.Mutex
+	token     string
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// get returns the latest token, reading the file again if it changed. If the
+// file can't be read or is empty, the previous token is kept.
+func (t *cachedTokenFile) get() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if now := time.Now(); t.lastCheck.IsZero() || now.Sub(t.lastCheck) >= t.interval {
+		t.lastCheck = now
+		if err := t.reloadLocked(); err != nil {
+			glog.Warningf("Unable to reload token file %s, using the previous token: %v", t.path, err)
+		}
+	}
+	return t.token
+}
+
+// invalidate makes the next get check the file for changes.
+func (t *cachedTokenFile) invalidate() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lastCheck = time.Time{}
+}
+
+func (t *cachedTokenFile) reloadLocked() error {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return err
+	}
+	if !t.modTime.IsZero() && info.ModTime().Equal(t.modTime) {
+		return nil
+	}
+	data, err := ioutil.ReadFile(t.path)
+	if err != nil {
+		return err
+	}
+	token := strings.TrimSpace(string(data))
+	if len(token) == 0 {
+		return fmt.Errorf("token file is empty")
+	}
+	t.token = token
+	t.modTime = info.ModTime()
+	return nil
+}
+
+type bearerTokenFileRoundTripper struct {
+	source *cachedTokenFile
+	rt     http.RoundTripper
+}
+
+// NewBearerTokenFileRoundTripper adds the bearer token read from tokenFile to a
+// request unless the authorization header has already been set. The file is
+// read again when it changes, and after the server rejected the token, so
+// that rotated tokens are picked up. bearer is used until the file was read.
+func NewBearerTokenFileRoundTripper(bearer, tokenFile string, rt http.RoundTripper) http.RoundTripper {
+	return &bearerTokenFileRoundTripper{
+		source: &cachedTokenFile{path: tokenFile, interval: tokenFileCheckInterval, token: bearer},
+		rt:     rt,
+	}
+}
+
+func (rt *bearerTokenFileRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(req.Header.Get("Authorization")) != 0 {
+		return rt.rt.RoundTrip(req)
+	}
+
+	req = cloneRequest(req)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", rt.source.get()))
+	resp, err := rt.rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		rt.source.invalidate()
+	}
+	return resp, err
+}
+
+func (rt *bearerTokenFileRoundTripper) CancelRequest(req *http.Request) {
+	if canceler, ok := rt.rt.(requestCanceler); ok {
+		canceler.CancelRequest(req)
+	} else {
+		glog.Errorf("CancelRequest not implemented")
+	}
+}
+
+func (rt *bearerTokenFileRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.rt }
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBearerTokenFileRoundTripper(t *testing.T) {
+	file, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(file.Name())
+	file.Close()
+	now := time.Now()
+	writeToken := func(token string, modTime time.Time) {
+		if err := ioutil.WriteFile(file.Name(), []byte(token), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.Chtimes(file.Name(), modTime, modTime); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	writeToken("first\n", now.Add(-time.Hour))
+
+	rt := &testRoundTripper{Response: &http.Response{StatusCode: http.StatusOK}}
+	tokenRT := NewBearerTokenFileRoundTripper("initial", file.Name(), rt).(*bearerTokenFileRoundTripper)
+	expectToken := func(expected string) {
+		if _, err := tokenRT.RoundTrip(&http.Request{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual := rt.Request.Header.Get("Authorization"); actual != "Bearer "+expected {
+			t.Errorf("expected token %q, got %q", expected, actual)
+		}
+	}
+
+	expectToken("first")
+
+	// changes are only noticed after the check interval
+	writeToken("second", now)
+	expectToken("first")
+	tokenRT.source.interval = 0
+	expectToken("second")
+	tokenRT.source.interval = time.Hour
+
+	// a rejected token makes the next request check the file
+	writeToken("third", now.Add(time.Hour))
+	rt.Response = &http.Response{StatusCode: http.StatusUnauthorized}
+	expectToken("second")
+	rt.Response = &http.Response{StatusCode: http.StatusOK}
+	expectToken("third")
+
+	// an unreadable file keeps the previous token
+	os.Remove(file.Name())
+	tokenRT.source.invalidate()
+	expectToken("third")
+
+	// a header set by the caller is kept
+	req := &http.Request{Header: http.Header{"Authorization": []string{"Bearer caller"}}}
+	tokenRT.RoundTrip(req)
+	if actual := rt.Request.Header.Get("Authorization"); actual != "Bearer caller" {
+		t.Errorf("expected the caller's token to be kept, got %q", actual)
+	}
+}
+
+func TestBearerTokenFileFallback(t *testing.T) {
+	rt := &testRoundTripper{Response: &http.Response{StatusCode: http.StatusOK}}
+	NewBearerTokenFileRoundTripper("initial", "/non/existent/token", rt).RoundTrip(&http.Request{})
+	if actual := rt.Request.Header.Get("Authorization"); actual != "Bearer initial" {
+		t.Errorf("expected the initial token, got %q", actual)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// TraceParentHeader is the W3C Trace Context header used to propagate the
+// span of a request to the server.
+const TraceParentHeader = "traceparent"
+
+// SpanContext identifies a span within a trace. It is the part of a span that
+// is propagated across process boundaries.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid returns true if both the trace and the span ID are set.
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// TraceParent formats the span context as the value of a W3C traceparent
+// header.
+func (c SpanContext) TraceParent() string {
+	flags := "00"
+	if c.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(c.TraceID[:]), hex.EncodeToString(c.SpanID[:]), flags)
+}
+
+// ParseTraceParent parses the value of a W3C traceparent header.
+func ParseTraceParent(value string) (SpanContext, error) {
+	var c SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return c, fmt.Errorf("invalid traceparent %q", value)
+	}
+	if len(parts[1]) != 2*len(c.TraceID) || len(parts[2]) != 2*len(c.SpanID) || len(parts[3]) != 2 {
+		return c, fmt.Errorf("invalid traceparent %q", value)
+	}
+	if _, err := hex.Decode(c.TraceID[:], []byte(parts[1])); err != nil {
+		return c, fmt.Errorf("invalid trace ID in traceparent %q: %v", value, err)
+	}
+	if _, err := hex.Decode(c.SpanID[:], []byte(parts[2])); err != nil {
+		return c, fmt.Errorf("invalid span ID in traceparent %q: %v", value, err)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return c, fmt.Errorf("invalid flags in traceparent %q: %v", value, err)
+	}
+	if !c.IsValid() {
+		return c, fmt.Errorf("invalid traceparent %q: all zero ID", value)
+	}
+	c.Sampled = flags[0]&1 == 1
+	return c, nil
+}
+
+// Span is a single timed operation within a trace.
+type Span interface {
+	// SpanContext returns the identity of the span.
+	SpanContext() SpanContext
+	// SetAttribute records a key value pair describing the operation. Values
+	// are strings, integers or booleans.
+	SetAttribute(key string, value interface{})
+	// End marks the operation as complete. Calls after the first are ignored.
+	End()
+}
+
+// Tracer starts spans. Implementations connect client-go to a tracing
+// system; NewInMemoryTracer returns one that keeps spans in memory.
+type Tracer interface {
+	// StartSpan starts a span called name, as a child of the span carried by
+	// ctx if there is one. It returns the span and a context carrying it.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+// newSpanID returns a random span ID.
+func newSpanID() [8]byte {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		glog.Errorf("Unable to generate a span ID: %v", err)
+	}
+	return id
+}
+
+// newTraceID returns a random trace ID.
+func newTraceID() [16]byte {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		glog.Errorf("Unable to generate a trace ID: %v", err)
+	}
+	return id
+}
+
+type tracingRoundTripper struct {
+	tracer Tracer
+	rt     http.RoundTripper
+}
+
+// NewTracingRoundTripper starts a span for every request sent through it, as
+// a child of the span in the request context if there is one, and sends the
+// span to the server in the traceparent header. The span ends once the
+// response headers have been received.
+func NewTracingRoundTripper(tracer Tracer, rt http.RoundTripper) http.RoundTripper {
+	return &tracingRoundTripper{tracer, rt}
+}
+
+func (rt *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := rt.tracer.StartSpan(req.Context(), "HTTP "+req.Method)
+	defer span.End()
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.url", req.URL.String())
+
+	req = cloneRequest(req).WithContext(ctx)
+	if sc := span.SpanContext(); sc.IsValid() {
+		req.Header.Set(TraceParentHeader, sc.TraceParent())
+	}
+	resp, err := rt.rt.RoundTrip(req)
+	if err != nil {
+		span.SetAttribute("error", err.Error())
+		return resp, err
+	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	return resp, nil
+}
+
+func (rt *tracingRoundTripper) CancelRequest(req *http.Request) {
+	if canceler, ok := rt.rt.(requestCanceler); ok {
+		canceler.CancelRequest(req)
+	} else {
+		glog.Errorf("CancelRequest not implemented")
+	}
+}
+
+func (rt *tracingRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.rt }
@@ -0,0 +1,98 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	valid := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	c, err := ParseTraceParent(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.IsValid() || !c.Sampled || c.SpanID != [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7} {
+		t.Errorf("unexpected span context: %#v", c)
+	}
+	if c.TraceParent() != valid {
+		t.Errorf("expected %q to round trip, got %q", valid, c.TraceParent())
+	}
+	if c, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future"); err != nil || c.Sampled {
+		t.Errorf("expected a later version to be accepted unsampled, got %#v: %v", c, err)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+	} {
+		if c, err := ParseTraceParent(invalid); err == nil {
+			t.Errorf("expected %q to be rejected, got %#v", invalid, c)
+		}
+	}
+}
+
+func TestTracingRoundTripper(t *testing.T) {
+	tracer := NewInMemoryTracer()
+	rt := &testRoundTripper{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	tracing := NewTracingRoundTripper(tracer, rt)
+
+	ctx, parent := tracer.StartSpan(context.Background(), "parent")
+	req, _ := http.NewRequest("GET", "https://localhost/api", nil)
+	if _, err := tracing.RoundTrip(req.WithContext(ctx)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Header.Get(TraceParentHeader)) != 0 {
+		t.Errorf("round tripper should not have modified the original request")
+	}
+	rt.Err = errors.New("connection refused")
+	if _, err := tracing.RoundTrip(req); err != rt.Err {
+		t.Fatalf("expected the round tripper error, got %v", err)
+	}
+	parent.End()
+
+	spans := tracer.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %#v", spans)
+	}
+	child, root := spans[0], spans[1]
+	if child.Name != "HTTP GET" || child.Parent != parent.SpanContext() || child.SpanContext.TraceID != parent.SpanContext().TraceID {
+		t.Errorf("expected a child of the span in the request context, got %#v", child)
+	}
+	if child.Attributes["http.status_code"] != http.StatusNotFound || child.Attributes["http.url"] != "https://localhost/api" {
+		t.Errorf("unexpected attributes: %v", child.Attributes)
+	}
+	if root.Parent.IsValid() || root.SpanContext.TraceID == parent.SpanContext().TraceID {
+		t.Errorf("expected a new trace for a request without a span, got %#v", root)
+	}
+	if root.Attributes["error"] != "connection refused" {
+		t.Errorf("expected the error to be recorded, got %v", root.Attributes)
+	}
+	// the header carries the span of the last request sent
+	if header := rt.Request.Header.Get(TraceParentHeader); header != root.SpanContext.TraceParent() {
+		t.Errorf("expected traceparent %q, got %q", root.SpanContext.TraceParent(), header)
+	}
+}
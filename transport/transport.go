@@ -28,7 +28,7 @@ import (
 // or transport level security defined by the provided Config.
 func New(config *Config) (http.RoundTripper, error) {
 	// Set transport level security
-	if config.Transport != nil && (config.HasCA() || config.HasCertAuth() || config.TLS.Insecure) {
+	if config.Transport != nil && (config.HasCA() || config.HasCertAuth() || config.HasCertCallback() || config.TLS.Insecure) {
 		return nil, fmt.Errorf("using a custom transport with TLS certificate options or the insecure flag is not allowed")
 	}
 
@@ -52,14 +52,21 @@ func New(config *Config) (http.RoundTripper, error) {
 // TLSConfigFor returns a tls.Config that will provide the transport level security defined
 // by the provided Config. Will return nil if no transport level security is requested.
 func TLSConfigFor(c *Config) (*tls.Config, error) {
-	if !(c.HasCA() || c.HasCertAuth() || c.TLS.Insecure) {
-		return nil, nil
+	tlsConfig, _, err := tlsConfigFor(c)
+	return tlsConfig, err
+}
+
+// tlsConfigFor returns the tls.Config for c, and the dynamic client certificate it uses
+// if the certificate is read from files.
+func tlsConfigFor(c *Config) (*tls.Config, *dynamicClientCert, error) {
+	if !(c.HasCA() || c.HasCertAuth() || c.HasCertCallback() || c.TLS.Insecure) {
+		return nil, nil, nil
 	}
 	if c.HasCA() && c.TLS.Insecure {
-		return nil, fmt.Errorf("specifying a root certificates file with the insecure flag is not allowed")
+		return nil, nil, fmt.Errorf("specifying a root certificates file with the insecure flag is not allowed")
 	}
 	if err := loadTLSFiles(c); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	tlsConfig := &tls.Config{
@@ -74,26 +81,60 @@ func TLSConfigFor(c *Config) (*tls.Config, error) {
 		tlsConfig.RootCAs = rootCertPool(c.TLS.CAData)
 	}
 
-	if c.HasCertAuth() {
+	var dynamicCert *dynamicClientCert
+	if certFromFiles(c) {
+		var err error
+		dynamicCert, err = newDynamicClientCert(c.TLS.CertFile, c.TLS.KeyFile, certCheckInterval)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig.GetClientCertificate = dynamicCert.GetClientCertificate
+	} else if c.HasCertAuth() {
 		cert, err := tls.X509KeyPair(c.TLS.CertData, c.TLS.KeyData)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	return tlsConfig, nil
+	if c.HasCertCallback() {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := c.TLS.GetCert()
+			if err != nil {
+				return nil, err
+			}
+			// GetClientCertificate must not return a nil certificate, an empty one
+			// means no certificate is sent
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		}
+	}
+
+	return tlsConfig, dynamicCert, nil
+}
+
+// certFromFiles returns whether the client certificate of c is read from CertFile and KeyFile,
+// in which case it is read again whenever the files change.
+func certFromFiles(c *Config) bool {
+	return len(c.TLS.CertFile) > 0 && len(c.TLS.KeyFile) > 0 &&
+		len(c.TLS.CertData) == 0 && len(c.TLS.KeyData) == 0 && !c.HasCertCallback()
 }
 
 // loadTLSFiles copies the data from the CertFile, KeyFile, and CAFile fields into the CertData,
 // KeyData, and CAFile fields, or returns an error. If no error is returned, all three fields are
-// either populated or were empty to start.
+// either populated or were empty to start. A client certificate read from files is left to
+// newDynamicClientCert.
 func loadTLSFiles(c *Config) error {
 	var err error
 	c.TLS.CAData, err = dataFromSliceOrFile(c.TLS.CAData, c.TLS.CAFile)
 	if err != nil {
 		return err
 	}
+	if certFromFiles(c) {
+		return nil
+	}
 
 	c.TLS.CertData, err = dataFromSliceOrFile(c.TLS.CertData, c.TLS.CertFile)
 	if err != nil {
@@ -0,0 +1,95 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package connrotation implements a connection dialer that tracks and can close
+// all the connections it created.
+//
+// This is used for credential rotation of long-lived connections, such as
+// those of watches and of HTTP/2, when there's no way to re-authenticate on a
+// live connection.
+package connrotation
+
+import (
+	"net"
+	"sync"
+)
+
+// DialFunc is a shorthand for the signature of net.Dial.
+type DialFunc func(network, address string) (net.Conn, error)
+
+// Dialer opens connections through Dial and tracks them.
+type Dialer struct {
+	dial DialFunc
+
+	lock  sync.Mutex
+	conns map[*closableConn]struct{}
+}
+
+// NewDialer creates a new Dialer instance.
+func NewDialer(dial DialFunc) *Dialer {
+	return &Dialer{
+		dial:  dial,
+		conns: make(map[*closableConn]struct{}),
+	}
+}
+
+// CloseAll forcibly closes all tracked connections.
+//
+// Note: new connections may get created before CloseAll returns.
+func (d *Dialer) CloseAll() {
+	d.lock.Lock()
+	conns := d.conns
+	d.conns = make(map[*closableConn]struct{})
+	d.lock.Unlock()
+
+	for conn := range conns {
+		conn.Close()
+	}
+}
+
+// Dial creates a new tracked connection.
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	conn, err := d.dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	closable := &closableConn{Conn: conn}
+	// When the connection is closed, stop tracking it. This is a no-op if
+	// the connection isn't tracked anymore, e.g. if CloseAll() was called.
+	closable.onClose = func() {
+		d.lock.Lock()
+		delete(d.conns, closable)
+		d.lock.Unlock()
+	}
+
+	// Start tracking the connection
+	d.lock.Lock()
+	d.conns[closable] = struct{}{}
+	d.lock.Unlock()
+
+	return closable, nil
+}
+
+type closableConn struct {
+	onClose func()
+	net.Conn
+}
+
+func (c *closableConn) Close() error {
+	c.onClose()
+	return c.Conn.Close()
+}
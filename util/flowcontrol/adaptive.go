@@ -0,0 +1,202 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flowcontrol
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/util/clock"
+)
+
+// adaptiveDecreaseInterval is the minimum time between two decreases of the
+// rate. Requests admitted before a decrease may still be throttled after it,
+// and must not cut the rate again.
+const adaptiveDecreaseInterval = time.Second
+
+// AdaptiveRateLimiter is a RateLimiter whose rate adapts to the responses of
+// the server, with additive increase and multiplicative decrease (AIMD): the
+// rate grows slowly while the server keeps up, and is cut when the server
+// throttles requests or slows down.
+type AdaptiveRateLimiter interface {
+	RateLimiter
+	// Observe reports the response to a request admitted by the rate limiter.
+	// throttled is true if the server asked the client to slow down, and
+	// retryAfter is how long the server asked to wait, if it did. No requests
+	// are admitted until then. latency is how long the server took to respond.
+	Observe(throttled bool, retryAfter, latency time.Duration)
+}
+
+// AdaptiveRateLimiterConfig holds the options of an adaptive rate limiter.
+type AdaptiveRateLimiterConfig struct {
+	// MinQPS and MaxQPS bound the rate. MinQPS defaults to 1, and MaxQPS to
+	// the larger of MinQPS and InitialQPS.
+	MinQPS float32
+	MaxQPS float32
+	// InitialQPS is the rate the rate limiter starts with. Defaults to MinQPS.
+	InitialQPS float32
+	// Burst is the number of requests admitted at once in excess of the rate.
+	// Defaults to 1.
+	Burst int
+	// Increase is how much the rate grows for every second worth of requests
+	// the server responded to in time. Defaults to 1.
+	Increase float32
+	// Decrease is the factor the rate is multiplied with when the server
+	// throttles a request or responds too slowly. Defaults to 0.5.
+	Decrease float32
+	// LatencyThreshold, if set, is the latency above which a response counts
+	// as a sign of an overloaded server.
+	LatencyThreshold time.Duration
+}
+
+type adaptiveRateLimiter struct {
+	config AdaptiveRateLimiterConfig
+	clock  clock.Clock
+
+	lock sync.Mutex
+	qps  float64
+	// tokens is the number of requests that could be admitted right away as
+	// of last. It is negative while admitted requests wait for their turn.
+	tokens float64
+	// last is when tokens was last refilled. It is in the future while the
+	// server asked to wait.
+	last         time.Time
+	lastDecrease time.Time
+}
+
+// NewAdaptiveRateLimiter creates a rate limiter which implements a token bucket whose rate
+// adapts to the responses of the server, within the bounds of config.
+func NewAdaptiveRateLimiter(config AdaptiveRateLimiterConfig) AdaptiveRateLimiter {
+	return newAdaptiveRateLimiter(config, clock.RealClock{})
+}
+
+func newAdaptiveRateLimiter(config AdaptiveRateLimiterConfig, c clock.Clock) *adaptiveRateLimiter {
+	if config.MinQPS <= 0 {
+		config.MinQPS = 1
+	}
+	if config.MaxQPS <= 0 {
+		config.MaxQPS = config.InitialQPS
+	}
+	if config.MaxQPS < config.MinQPS {
+		config.MaxQPS = config.MinQPS
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.Increase <= 0 {
+		config.Increase = 1
+	}
+	if config.Decrease <= 0 || config.Decrease >= 1 {
+		config.Decrease = 0.5
+	}
+	l := &adaptiveRateLimiter{
+		config: config,
+		clock:  c,
+		tokens: float64(config.Burst),
+		last:   c.Now(),
+	}
+	l.qps = l.boundQPS(float64(config.InitialQPS))
+	return l
+}
+
+func (l *adaptiveRateLimiter) TryAccept() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	l.refillLocked(now)
+	if l.last.After(now) || l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Accept will block until a token becomes available
+func (l *adaptiveRateLimiter) Accept() {
+	l.lock.Lock()
+	now := l.clock.Now()
+	l.refillLocked(now)
+	l.tokens--
+	// wait for the server, then for the tokens taken by earlier requests
+	wait := l.last.Sub(now)
+	if l.tokens < 0 {
+		wait += time.Duration(-l.tokens / l.qps * float64(time.Second))
+	}
+	l.lock.Unlock()
+
+	if wait > 0 {
+		l.clock.Sleep(wait)
+	}
+}
+
+func (l *adaptiveRateLimiter) Stop() {
+}
+
+func (l *adaptiveRateLimiter) Saturation() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.refillLocked(l.clock.Now())
+	if l.tokens <= 0 {
+		return 1
+	}
+	return 1 - l.tokens/float64(l.config.Burst)
+}
+
+func (l *adaptiveRateLimiter) QPS() float32 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return float32(l.qps)
+}
+
+func (l *adaptiveRateLimiter) Observe(throttled bool, retryAfter, latency time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	l.refillLocked(now)
+	if until := now.Add(retryAfter); retryAfter > 0 && until.After(l.last) {
+		l.tokens = math.Min(l.tokens, 0)
+		l.last = until
+	}
+
+	if throttled || (l.config.LatencyThreshold > 0 && latency > l.config.LatencyThreshold) {
+		if now.Sub(l.lastDecrease) >= adaptiveDecreaseInterval {
+			l.qps = l.boundQPS(l.qps * float64(l.config.Decrease))
+			l.lastDecrease = now
+		}
+		return
+	}
+	// grow by Increase once a second worth of requests succeeded
+	l.qps = l.boundQPS(l.qps + float64(l.config.Increase)/l.qps)
+}
+
+// refillLocked adds the tokens accumulated at the current rate since the last
+// refill, up to the burst.
+func (l *adaptiveRateLimiter) refillLocked(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+	l.tokens = math.Min(l.tokens+now.Sub(l.last).Seconds()*l.qps, float64(l.config.Burst))
+	l.last = now
+}
+
+func (l *adaptiveRateLimiter) boundQPS(qps float64) float64 {
+	return math.Max(float64(l.config.MinQPS), math.Min(qps, float64(l.config.MaxQPS)))
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flowcontrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/util/clock"
+)
+
+func TestAdaptiveRateLimiterAIMD(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	l := newAdaptiveRateLimiter(AdaptiveRateLimiterConfig{
+		MinQPS:           2,
+		MaxQPS:           10,
+		InitialQPS:       4,
+		Increase:         1,
+		Decrease:         0.5,
+		LatencyThreshold: time.Second,
+	}, fakeClock)
+
+	if qps := l.QPS(); qps != 4 {
+		t.Fatalf("expected to start at 4 qps, got %v", qps)
+	}
+	// a second worth of fast responses adds 1 qps
+	for i := 0; i < 4; i++ {
+		l.Observe(false, 0, 10*time.Millisecond)
+	}
+	if qps := l.QPS(); qps < 4.9 || qps > 5.1 {
+		t.Errorf("expected about 5 qps after a second of successes, got %v", qps)
+	}
+
+	l.Observe(true, 0, 10*time.Millisecond)
+	if qps := l.QPS(); qps < 2.4 || qps > 2.6 {
+		t.Errorf("expected the rate to halve when throttled, got %v", qps)
+	}
+	// responses to requests sent before the decrease don't cut the rate again
+	l.Observe(true, 0, 10*time.Millisecond)
+	if qps := l.QPS(); qps < 2.4 || qps > 2.6 {
+		t.Errorf("expected a single decrease within a second, got %v", qps)
+	}
+	// slow responses count as overload, and the rate stays above the minimum
+	fakeClock.Step(time.Second)
+	l.Observe(false, 0, 2*time.Second)
+	if qps := l.QPS(); qps != 2 {
+		t.Errorf("expected the rate to be capped at the minimum, got %v", qps)
+	}
+
+	for i := 0; i < 1000; i++ {
+		l.Observe(false, 0, 0)
+	}
+	if qps := l.QPS(); qps != 10 {
+		t.Errorf("expected the rate to be capped at the maximum, got %v", qps)
+	}
+}
+
+func TestAdaptiveRateLimiterDefaults(t *testing.T) {
+	l := newAdaptiveRateLimiter(AdaptiveRateLimiterConfig{InitialQPS: 20}, clock.NewFakeClock(time.Now()))
+	if qps := l.QPS(); qps != 20 {
+		t.Errorf("expected to start at the initial qps, got %v", qps)
+	}
+	l.Observe(true, 0, 0)
+	if qps := l.QPS(); qps != 10 {
+		t.Errorf("expected the rate to halve when throttled, got %v", qps)
+	}
+
+	l = newAdaptiveRateLimiter(AdaptiveRateLimiterConfig{}, clock.NewFakeClock(time.Now()))
+	if qps := l.QPS(); qps != 1 {
+		t.Errorf("expected to start at the minimum qps, got %v", qps)
+	}
+}
+
+func TestAdaptiveRateLimiterAccept(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	l := newAdaptiveRateLimiter(AdaptiveRateLimiterConfig{MinQPS: 10, Burst: 2}, fakeClock)
+
+	if s := l.Saturation(); s != 0 {
+		t.Errorf("expected a full bucket, got saturation %v", s)
+	}
+	if !l.TryAccept() || !l.TryAccept() {
+		t.Fatalf("expected the burst to be admitted")
+	}
+	if l.TryAccept() {
+		t.Errorf("expected no token to be left")
+	}
+	if s := l.Saturation(); s != 1 {
+		t.Errorf("expected an empty bucket, got saturation %v", s)
+	}
+
+	start := fakeClock.Now()
+	l.Accept()
+	if waited := fakeClock.Since(start); waited != 100*time.Millisecond {
+		t.Errorf("expected to wait for a token at 10 qps, waited %v", waited)
+	}
+
+	// the server asking to wait holds back all requests
+	l.Observe(true, 5*time.Second, 0)
+	fakeClock.Step(time.Second)
+	if l.TryAccept() {
+		t.Errorf("expected no request to be admitted before Retry-After expired")
+	}
+	start = fakeClock.Now()
+	l.Accept()
+	if waited := fakeClock.Since(start); waited < 4*time.Second {
+		t.Errorf("expected to wait for the rest of Retry-After, waited %v", waited)
+	}
+}
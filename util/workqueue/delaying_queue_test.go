@@ -18,6 +18,7 @@ package workqueue
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
 	"testing"
 	"time"
@@ -215,6 +216,58 @@ func TestCopyShifting(t *testing.T) {
 	}
 }
 
+// BenchmarkDelayingQueueAddAfter measures AddAfter for new items while a large
+// number of items is already waiting, as happens after an apiserver outage.
+func BenchmarkDelayingQueueAddAfter(b *testing.B) {
+	for _, backlog := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("backlog-%d", backlog), func(b *testing.B) {
+			q, r := newBackloggedQueue(b, backlog)
+			defer q.ShutDown()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				q.AddAfter(backlog+i, time.Hour+time.Duration(r.Int63n(int64(time.Hour))))
+			}
+			if err := waitForWaitingQueueToFill(q); err != nil {
+				b.Fatalf("unexpected err: %v", err)
+			}
+		})
+	}
+}
+
+// BenchmarkDelayingQueueAddAfterEarlier measures AddAfter moving items that are
+// already waiting to an earlier time.
+func BenchmarkDelayingQueueAddAfterEarlier(b *testing.B) {
+	for _, backlog := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("backlog-%d", backlog), func(b *testing.B) {
+			q, r := newBackloggedQueue(b, backlog)
+			defer q.ShutDown()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				q.AddAfter(r.Intn(backlog), time.Duration(backlog-i%backlog)*time.Millisecond)
+			}
+			if err := waitForWaitingQueueToFill(q); err != nil {
+				b.Fatalf("unexpected err: %v", err)
+			}
+		})
+	}
+}
+
+// newBackloggedQueue returns a queue with backlog items waiting between one and
+// two hours on a fake clock.
+func newBackloggedQueue(b *testing.B, backlog int) (DelayingInterface, *rand.Rand) {
+	q := newDelayingQueue(clock.NewFakeClock(time.Now()), "")
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < backlog; i++ {
+		q.AddAfter(i, time.Hour+time.Duration(r.Int63n(int64(time.Hour))))
+	}
+	if err := waitForWaitingQueueToFill(q); err != nil {
+		b.Fatalf("unexpected err: %v", err)
+	}
+	return q, r
+}
+
 func waitForAdded(q DelayingInterface, depth int) error {
 	return wait.Poll(1*time.Millisecond, 10*time.Second, func() (done bool, err error) {
 		if q.Len() == depth {
@@ -0,0 +1,271 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lavalamp/client-go-flat/util/clock"
+)
+
+// PriorityFunc returns the priority of an item.  Items with a higher priority
+// are always handed out before items with a lower priority.
+type PriorityFunc func(item interface{}) int
+
+// TenantFunc returns the tenant an item belongs to.  Within a priority, items
+// are handed out round-robin across tenants, so that a burst of items from one
+// tenant does not starve the others.
+type TenantFunc func(item interface{}) string
+
+// NamespaceTenant is a TenantFunc for namespace/name keys as produced by
+// cache.MetaNamespaceKeyFunc.  Keys of cluster scoped objects, and items that
+// are not strings, all belong to the empty tenant.
+func NamespaceTenant(item interface{}) string {
+	key, ok := item.(string)
+	if !ok {
+		return ""
+	}
+	if i := strings.Index(key, "/"); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// NewFairQueue constructs a queue that hands out items by priority and, within
+// a priority, round-robin across tenants.  Either function may be nil, in which
+// case all items share the same priority or tenant.  With both nil the queue
+// behaves like the one returned by New.
+func NewFairQueue(priority PriorityFunc, tenant TenantFunc) *FairType {
+	return NewNamedFairQueue("", priority, tenant)
+}
+
+func NewNamedFairQueue(name string, priority PriorityFunc, tenant TenantFunc) *FairType {
+	q := &FairType{
+		priority:   priority,
+		tenant:     tenant,
+		levels:     map[int]*priorityLevel{},
+		dirty:      set{},
+		processing: set{},
+		cond:       sync.NewCond(&sync.Mutex{}),
+		metrics:    newQueueMetrics(name),
+	}
+	if len(name) > 0 {
+		go updateUnfinishedWorkLoop(q, q.cond.L, q.metrics)
+	}
+	return q
+}
+
+// NewFairRateLimitingQueue constructs a rate limiting queue on top of a fair
+// queue.  Items added with AddAfter or AddRateLimited take their place by
+// priority and tenant once their delay has passed.
+func NewFairRateLimitingQueue(rateLimiter RateLimiter, name string, priority PriorityFunc, tenant TenantFunc) RateLimitingInterface {
+	return &rateLimitingType{
+		DelayingInterface: newDelayingQueueFrom(clock.RealClock{}, NewNamedFairQueue(name, priority, tenant), name),
+		rateLimiter:       rateLimiter,
+	}
+}
+
+// FairType is a work queue that orders items by priority and tenant.  It keeps
+// the guarantees of Type: an item is never processed concurrently, and an item
+// added several times before it is processed is processed once.
+type FairType struct {
+	priority PriorityFunc
+	tenant   TenantFunc
+
+	// levels holds the waiting items by priority. Every waiting item is in
+	// the dirty set and not in the processing set.
+	levels map[int]*priorityLevel
+	// priorities lists the keys of levels, highest first.
+	priorities []int
+	// length is the number of waiting items across all levels.
+	length int
+
+	// dirty defines all of the items that need to be processed.
+	dirty set
+
+	// Things that are currently being processed are in the processing set.
+	// These things may be simultaneously in the dirty set. When we finish
+	// processing something and remove it from this set, we'll check if
+	// it's in the dirty set, and if so, add it to the queue.
+	processing set
+
+	cond *sync.Cond
+
+	shuttingDown bool
+
+	metrics queueMetrics
+}
+
+// priorityLevel holds the waiting items of a single priority, by tenant.
+type priorityLevel struct {
+	// queues holds the waiting items of each tenant in FIFO order.
+	queues map[string][]t
+	// tenants lists the tenants with waiting items in round-robin order.
+	tenants []string
+	// next is the index in tenants of the tenant served next.
+	next int
+}
+
+func (l *priorityLevel) push(tenant string, item t) {
+	queue, exists := l.queues[tenant]
+	if !exists {
+		l.tenants = append(l.tenants, tenant)
+	}
+	l.queues[tenant] = append(queue, item)
+}
+
+func (l *priorityLevel) pop() t {
+	tenant := l.tenants[l.next]
+	queue := l.queues[tenant]
+	item := queue[0]
+	queue[0] = nil
+	if len(queue) == 1 {
+		// the tenant has nothing left to wait for, so the following tenant
+		// moves into its slot
+		delete(l.queues, tenant)
+		l.tenants = append(l.tenants[:l.next], l.tenants[l.next+1:]...)
+	} else {
+		l.queues[tenant] = queue[1:]
+		l.next++
+	}
+	if l.next >= len(l.tenants) {
+		l.next = 0
+	}
+	return item
+}
+
+func (q *FairType) push(item t) {
+	priority := 0
+	if q.priority != nil {
+		priority = q.priority(item)
+	}
+	tenant := ""
+	if q.tenant != nil {
+		tenant = q.tenant(item)
+	}
+
+	level, exists := q.levels[priority]
+	if !exists {
+		level = &priorityLevel{queues: map[string][]t{}}
+		q.levels[priority] = level
+		q.priorities = append(q.priorities, priority)
+		sort.Sort(sort.Reverse(sort.IntSlice(q.priorities)))
+	}
+	level.push(tenant, item)
+	q.length++
+	q.cond.Signal()
+}
+
+func (q *FairType) pop() t {
+	priority := q.priorities[0]
+	level := q.levels[priority]
+	item := level.pop()
+	if len(level.tenants) == 0 {
+		delete(q.levels, priority)
+		q.priorities = q.priorities[1:]
+	}
+	q.length--
+	return item
+}
+
+// Add marks item as needing processing.
+func (q *FairType) Add(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if q.dirty.has(item) {
+		return
+	}
+
+	q.metrics.add(item)
+
+	q.dirty.insert(item)
+	if q.processing.has(item) {
+		return
+	}
+
+	q.push(item)
+}
+
+// Len returns the current queue length, for informational purposes only. You
+// shouldn't e.g. gate a call to Add() or Get() on Len() being a particular
+// value, that can't be synchronized properly.
+func (q *FairType) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.length
+}
+
+// Get blocks until it can return an item to be processed. If shutdown = true,
+// the caller should end their goroutine. You must call Done with item when you
+// have finished processing it.
+func (q *FairType) Get() (item interface{}, shutdown bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for q.length == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if q.length == 0 {
+		// We must be shutting down.
+		return nil, true
+	}
+
+	item = q.pop()
+
+	q.metrics.get(item)
+
+	q.processing.insert(item)
+	q.dirty.delete(item)
+
+	return item, false
+}
+
+// Done marks item as done processing, and if it has been marked as dirty again
+// while it was being processed, it will be re-added to the queue for
+// re-processing.
+func (q *FairType) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.metrics.done(item)
+
+	q.processing.delete(item)
+	if q.dirty.has(item) {
+		q.push(item)
+	}
+}
+
+// ShutDown will cause q to ignore all new items added to it. As soon as the
+// worker goroutines have drained the existing items in the queue, they will be
+// instructed to exit.
+func (q *FairType) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func (q *FairType) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	return q.shuttingDown
+}
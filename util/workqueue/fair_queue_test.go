@@ -0,0 +1,120 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavalamp/client-go-flat/util/workqueue"
+)
+
+func drain(q workqueue.Interface) []interface{} {
+	var items []interface{}
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		q.Done(item)
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestFairQueueRoundRobin(t *testing.T) {
+	q := workqueue.NewFairQueue(nil, workqueue.NamespaceTenant)
+	for _, key := range []string{"noisy/1", "noisy/2", "noisy/3", "a/1", "b/1", "a/2", "cluster-scoped"} {
+		q.Add(key)
+	}
+
+	expected := []interface{}{"noisy/1", "a/1", "b/1", "cluster-scoped", "noisy/2", "a/2", "noisy/3"}
+	if got := drain(q); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestFairQueuePriority(t *testing.T) {
+	priority := func(item interface{}) int {
+		if strings.HasSuffix(item.(string), "urgent") {
+			return 10
+		}
+		return 0
+	}
+	q := workqueue.NewFairQueue(priority, workqueue.NamespaceTenant)
+	for _, key := range []string{"a/1", "a/2", "b/1", "a/urgent", "c/urgent"} {
+		q.Add(key)
+	}
+
+	expected := []interface{}{"a/urgent", "c/urgent", "a/1", "b/1", "a/2"}
+	if got := drain(q); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestFairQueueDedup(t *testing.T) {
+	q := workqueue.NewFairQueue(nil, workqueue.NamespaceTenant)
+	q.Add("a/1")
+	q.Add("a/1")
+	if q.Len() != 1 {
+		t.Fatalf("expected a single item, got %d", q.Len())
+	}
+
+	item, _ := q.Get()
+	// re-added while processing, so it must wait for Done
+	q.Add("a/1")
+	q.Add("b/1")
+	if next, _ := q.Get(); next != "b/1" {
+		t.Errorf("expected b/1, got %v", next)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected the item being processed to stay out of the queue")
+	}
+	q.Done(item)
+	if q.Len() != 1 {
+		t.Fatalf("expected the item to be requeued after Done, got %d", q.Len())
+	}
+	if next, _ := q.Get(); next != "a/1" {
+		t.Errorf("expected a/1, got %v", next)
+	}
+
+	q.ShutDown()
+	if _, shutdown := q.Get(); !shutdown {
+		t.Errorf("expected shutdown")
+	}
+}
+
+func TestFairRateLimitingQueue(t *testing.T) {
+	limiter := workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond)
+	q := workqueue.NewFairRateLimitingQueue(limiter, "", nil, workqueue.NamespaceTenant)
+	defer q.ShutDown()
+
+	q.AddRateLimited("a/1")
+	q.AddAfter("b/1", time.Millisecond)
+	got := map[interface{}]bool{}
+	for len(got) < 2 {
+		item, _ := q.Get()
+		q.Done(item)
+		q.Forget(item)
+		got[item] = true
+	}
+	if !got["a/1"] || !got["b/1"] {
+		t.Errorf("unexpected items %v", got)
+	}
+	if q.NumRequeues("a/1") != 0 {
+		t.Errorf("expected a/1 to be forgotten")
+	}
+}
@@ -28,6 +28,7 @@ type queueMetrics interface {
 	add(item t)
 	get(item t)
 	done(item t)
+	updateUnfinishedWork()
 }
 
 // GaugeMetric represents a single numerical value that can arbitrarily go up
@@ -37,6 +38,12 @@ type GaugeMetric interface {
 	Dec()
 }
 
+// SettableGaugeMetric represents a single numerical value that can
+// arbitrarily go up and down and is set rather than incremented.
+type SettableGaugeMetric interface {
+	Set(float64)
+}
+
 // CounterMetric represents a single numerical value that only ever
 // goes up.
 type CounterMetric interface {
@@ -52,6 +59,7 @@ type noopMetric struct{}
 
 func (noopMetric) Inc()            {}
 func (noopMetric) Dec()            {}
+func (noopMetric) Set(float64)     {}
 func (noopMetric) Observe(float64) {}
 
 type defaultQueueMetrics struct {
@@ -62,9 +70,15 @@ type defaultQueueMetrics struct {
 	// how long an item stays in a workqueue
 	latency SummaryMetric
 	// how long processing an item from a workqueue takes
-	workDuration         SummaryMetric
-	addTimes             map[t]time.Time
-	processingStartTimes map[t]time.Time
+	workDuration SummaryMetric
+	// how long the items currently being processed have been processed
+	// for in total, in seconds
+	unfinishedWorkSeconds SettableGaugeMetric
+	// how long the item processed for the longest time has been
+	// processed for, in seconds
+	longestRunningProcessor SettableGaugeMetric
+	addTimes                map[t]time.Time
+	processingStartTimes    map[t]time.Time
 }
 
 func (m *defaultQueueMetrics) add(item t) {
@@ -103,6 +117,42 @@ func (m *defaultQueueMetrics) done(item t) {
 	}
 }
 
+func (m *defaultQueueMetrics) updateUnfinishedWork() {
+	if m == nil {
+		return
+	}
+
+	var total, oldest float64
+	for _, startTime := range m.processingStartTimes {
+		age := time.Since(startTime).Seconds()
+		total += age
+		if age > oldest {
+			oldest = age
+		}
+	}
+	m.unfinishedWorkSeconds.Set(total)
+	m.longestRunningProcessor.Set(oldest)
+}
+
+// unfinishedWorkUpdatePeriod is how often the work in progress of a named
+// queue is reported.
+const unfinishedWorkUpdatePeriod = 500 * time.Millisecond
+
+// updateUnfinishedWorkLoop reports the work in progress of q until it shuts
+// down.  lock must be the lock guarding q's metrics.
+func updateUnfinishedWorkLoop(q Interface, lock sync.Locker, metrics queueMetrics) {
+	ticker := time.NewTicker(unfinishedWorkUpdatePeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		if q.ShuttingDown() {
+			return
+		}
+		lock.Lock()
+		metrics.updateUnfinishedWork()
+		lock.Unlock()
+	}
+}
+
 // Gets the time since the specified start in microseconds.
 func sinceInMicroseconds(start time.Time) float64 {
 	return float64(time.Since(start).Nanoseconds() / time.Microsecond.Nanoseconds())
@@ -131,6 +181,8 @@ type MetricsProvider interface {
 	NewLatencyMetric(name string) SummaryMetric
 	NewWorkDurationMetric(name string) SummaryMetric
 	NewRetriesMetric(name string) CounterMetric
+	NewUnfinishedWorkSecondsMetric(name string) SettableGaugeMetric
+	NewLongestRunningProcessorSecondsMetric(name string) SettableGaugeMetric
 }
 
 type noopMetricsProvider struct{}
@@ -155,6 +207,14 @@ func (_ noopMetricsProvider) NewRetriesMetric(name string) CounterMetric {
 	return noopMetric{}
 }
 
+func (_ noopMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) SettableGaugeMetric {
+	return noopMetric{}
+}
+
+func (_ noopMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) SettableGaugeMetric {
+	return noopMetric{}
+}
+
 var metricsFactory = struct {
 	metricsProvider MetricsProvider
 	setProviders    sync.Once
@@ -168,12 +228,14 @@ func newQueueMetrics(name string) queueMetrics {
 		return ret
 	}
 	return &defaultQueueMetrics{
-		depth:                metricsFactory.metricsProvider.NewDepthMetric(name),
-		adds:                 metricsFactory.metricsProvider.NewAddsMetric(name),
-		latency:              metricsFactory.metricsProvider.NewLatencyMetric(name),
-		workDuration:         metricsFactory.metricsProvider.NewWorkDurationMetric(name),
-		addTimes:             map[t]time.Time{},
-		processingStartTimes: map[t]time.Time{},
+		depth:                   metricsFactory.metricsProvider.NewDepthMetric(name),
+		adds:                    metricsFactory.metricsProvider.NewAddsMetric(name),
+		latency:                 metricsFactory.metricsProvider.NewLatencyMetric(name),
+		workDuration:            metricsFactory.metricsProvider.NewWorkDurationMetric(name),
+		unfinishedWorkSeconds:   metricsFactory.metricsProvider.NewUnfinishedWorkSecondsMetric(name),
+		longestRunningProcessor: metricsFactory.metricsProvider.NewLongestRunningProcessorSecondsMetric(name),
+		addTimes:                map[t]time.Time{},
+		processingStartTimes:    map[t]time.Time{},
 	}
 }
 
@@ -17,36 +17,110 @@ limitations under the License.
 package workqueue
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 
+	utilerrors "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/errors"
 	utilruntime "github.com/lavalamp/client-go-flat/apimachinery/pkg/util/runtime"
 )
 
 type DoWorkPieceFunc func(piece int)
 
+// DoWorkPieceWithErrorFunc processes a single piece of work.  ctx is done when
+// the remaining work has been cancelled.
+type DoWorkPieceWithErrorFunc func(ctx context.Context, piece int) error
+
+// ParallelizeOptions tunes ParallelizeUntil.
+type ParallelizeOptions struct {
+	// ChunkSize is the number of consecutive pieces a worker takes at a
+	// time.  Larger chunks reduce the overhead of handing out cheap pieces.
+	// Values below 1 mean 1.
+	ChunkSize int
+	// FailFast stops handing out pieces once a piece has failed.  Otherwise
+	// every piece is processed and every failure is reported.
+	FailFast bool
+}
+
 // Parallelize is a very simple framework that allow for parallelizing
 // N independent pieces of work.
 func Parallelize(workers, pieces int, doWorkPiece DoWorkPieceFunc) {
-	toProcess := make(chan int, pieces)
-	for i := 0; i < pieces; i++ {
+	ParallelizeUntil(context.Background(), workers, pieces, func(_ context.Context, piece int) error {
+		doWorkPiece(piece)
+		return nil
+	}, ParallelizeOptions{})
+}
+
+// ParallelizeUntil processes N independent pieces of work with the given
+// number of workers, and stops handing out pieces once ctx is done.  The
+// errors returned by the pieces are aggregated in piece order.  If ctx ended
+// before every piece was processed, ctx.Err() is added to the aggregate.
+func ParallelizeUntil(ctx context.Context, workers, pieces int, doWorkPiece DoWorkPieceWithErrorFunc, opts ParallelizeOptions) utilerrors.Aggregate {
+	if pieces <= 0 {
+		return nil
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	chunks := (pieces + chunkSize - 1) / chunkSize
+	toProcess := make(chan int, chunks)
+	for i := 0; i < chunks; i++ {
 		toProcess <- i
 	}
 	close(toProcess)
 
-	if pieces < workers {
-		workers = pieces
+	if chunks < workers {
+		workers = chunks
 	}
 
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// each piece writes only its own slot, so no locking is needed
+	errs := make([]error, pieces)
+	var processed int64
+
 	wg := sync.WaitGroup{}
 	wg.Add(workers)
 	for i := 0; i < workers; i++ {
 		go func() {
 			defer utilruntime.HandleCrash()
 			defer wg.Done()
-			for piece := range toProcess {
-				doWorkPiece(piece)
+			for chunk := range toProcess {
+				start := chunk * chunkSize
+				end := start + chunkSize
+				if end > pieces {
+					end = pieces
+				}
+				for piece := start; piece < end; piece++ {
+					select {
+					case <-workCtx.Done():
+						return
+					default:
+					}
+					if err := doWorkPiece(workCtx, piece); err != nil {
+						errs[piece] = err
+						if opts.FailFast {
+							cancel()
+						}
+					}
+					atomic.AddInt64(&processed, 1)
+				}
 			}
 		}()
 	}
 	wg.Wait()
+
+	var errList []error
+	for _, err := range errs {
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if ctx.Err() != nil && atomic.LoadInt64(&processed) < int64(pieces) {
+		errList = append(errList, ctx.Err())
+	}
+	return utilerrors.NewAggregate(errList)
 }
@@ -0,0 +1,103 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelizeUntil(t *testing.T) {
+	for _, chunkSize := range []int{0, 1, 3, 100} {
+		var seen [10]int32
+		errs := ParallelizeUntil(context.Background(), 4, len(seen), func(ctx context.Context, piece int) error {
+			atomic.AddInt32(&seen[piece], 1)
+			if piece%4 == 1 {
+				return fmt.Errorf("piece %d", piece)
+			}
+			return nil
+		}, ParallelizeOptions{ChunkSize: chunkSize})
+
+		for piece, count := range seen {
+			if count != 1 {
+				t.Errorf("chunk size %d: piece %d processed %d times", chunkSize, piece, count)
+			}
+		}
+		if errs == nil {
+			t.Fatalf("chunk size %d: expected errors", chunkSize)
+		}
+		expected := []error{fmt.Errorf("piece 1"), fmt.Errorf("piece 5"), fmt.Errorf("piece 9")}
+		if !reflect.DeepEqual(errs.Errors(), expected) {
+			t.Errorf("chunk size %d: expected %v, got %v", chunkSize, expected, errs.Errors())
+		}
+	}
+}
+
+func TestParallelizeUntilFailFast(t *testing.T) {
+	var processed int32
+	errs := ParallelizeUntil(context.Background(), 1, 100, func(ctx context.Context, piece int) error {
+		atomic.AddInt32(&processed, 1)
+		if piece == 2 {
+			return fmt.Errorf("piece %d", piece)
+		}
+		return nil
+	}, ParallelizeOptions{FailFast: true})
+
+	if processed != 3 {
+		t.Errorf("expected work to stop after the failing piece, processed %d", processed)
+	}
+	if errs == nil || len(errs.Errors()) != 1 {
+		t.Errorf("expected only the failure, got %v", errs)
+	}
+}
+
+func TestParallelizeUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var processed int32
+	errs := ParallelizeUntil(ctx, 1, 100, func(ctx context.Context, piece int) error {
+		if atomic.AddInt32(&processed, 1) == 5 {
+			cancel()
+		}
+		return nil
+	}, ParallelizeOptions{ChunkSize: 10})
+
+	if processed != 5 {
+		t.Errorf("expected work to stop once the context ended, processed %d", processed)
+	}
+	if errs == nil || !reflect.DeepEqual(errs.Errors(), []error{context.Canceled}) {
+		t.Errorf("expected the context error, got %v", errs)
+	}
+
+	if errs := ParallelizeUntil(ctx, 1, 0, nil, ParallelizeOptions{}); errs != nil {
+		t.Errorf("expected no errors for no work, got %v", errs)
+	}
+}
+
+func TestParallelize(t *testing.T) {
+	var seen [50]int32
+	Parallelize(8, len(seen), func(piece int) {
+		atomic.AddInt32(&seen[piece], 1)
+	})
+	for piece, count := range seen {
+		if count != 1 {
+			t.Errorf("piece %d processed %d times", piece, count)
+		}
+	}
+}
@@ -35,12 +35,16 @@ func New() *Type {
 }
 
 func NewNamed(name string) *Type {
-	return &Type{
+	q := &Type{
 		dirty:      set{},
 		processing: set{},
 		cond:       sync.NewCond(&sync.Mutex{}),
 		metrics:    newQueueMetrics(name),
 	}
+	if len(name) > 0 {
+		go updateUnfinishedWorkLoop(q, q.cond.L, q.metrics)
+	}
+	return q
 }
 
 // Type is a work queue (see the package comment).